@@ -51,15 +51,29 @@ func run(ctx context.Context) int {
 	}
 	clock := clock.NewZonedClock(loc)
 
-	bot, err := internal.NewBot(*conf, todoistClient, clock, log)
+	store, err := internal.OpenFileStore(conf.StateFile)
+	if err != nil {
+		log.ErrorContext(ctx, "failed to open state store", "error", err, "path", conf.StateFile)
+		return 1
+	}
+
+	channels := internal.NewChannels(conf, httpClient, store)
+	if !conf.TelegramEnabled() {
+		log.InfoContext(ctx, "telegram is disabled, notifying channels only", "channels", len(channels))
+		bot := internal.NewBot(*conf, nil, todoistClient, store, clock, log, channels...)
+		return schedule(ctx, conf, bot, clock, loc, log)
+	}
+
+	telegramBot, err := internal.NewTelegramBot(conf.TelegramToken)
 	if err != nil {
 		log.ErrorContext(ctx, "failed to create bot", "error", err)
 		return 1
 	}
+	bot := internal.NewBot(*conf, internal.NewTelegramMessenger(telegramBot), todoistClient, store, clock, log, channels...)
 
 	botErrChan := make(chan error, 1)
 	go func() {
-		if err := bot.Start(ctx); err != nil {
+		if err := bot.Start(ctx, telegramBot); err != nil {
 			botErrChan <- err
 		}
 	}()
@@ -72,34 +86,105 @@ func run(ctx context.Context) int {
 		log.InfoContext(ctx, "bot started successfully")
 	}
 
+	return schedule(ctx, conf, bot, clock, loc, log)
+}
+
+// schedule runs the notification jobs until the process is stopped.
+func schedule(ctx context.Context, conf *internal.Config, bot *internal.Bot, clock internal.Clock, loc *time.Location, log *slog.Logger) int {
 	scheduler, err := gocron.NewScheduler(gocron.WithLocation(loc))
 	if err != nil {
 		log.ErrorContext(ctx, "failed to create scheduler", "error", err)
 		return 1
 	}
 
-	job, err := scheduler.NewJob(
-		gocron.CronJob(conf.Schedule, false),
-		gocron.NewTask(func() {
-			if err := bot.SendTasks(conf.TelegramChatID, false); err != nil {
-				log.ErrorContext(ctx, "failed to send notification", "error", err)
-			}
-		}),
-	)
-	if err != nil {
-		log.ErrorContext(ctx, "failed to create job", "error", err, "schedule", conf.Schedule)
-		return 1
+	jobs := make(map[string]gocron.Job)
+	profiles := conf.AllProfiles()
+	if !conf.TelegramEnabled() && !conf.HasChannels() {
+		// only the email digest is configured, scheduled notifications have no target
+		profiles = nil
+	}
+	for _, profile := range profiles {
+		job, err := scheduler.NewJob(
+			gocron.CronJob(profile.Schedule, false),
+			gocron.NewTask(func() {
+				if active := conf.ActiveProfile(clock.Now()); active.Name != profile.Name {
+					return
+				}
+				if err := bot.SendTasks(conf.TelegramChatID, false); err != nil {
+					log.ErrorContext(ctx, "failed to send notification", "error", err, "profile", profile.Name)
+				}
+			}),
+		)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create job", "error", err, "profile", profile.Name, "schedule", profile.Schedule)
+			return 1
+		}
+		jobs[profile.Name] = job
+	}
+
+	if conf.TomorrowPreviewSchedule != "" && conf.TelegramEnabled() {
+		job, err := scheduler.NewJob(
+			gocron.CronJob(conf.TomorrowPreviewSchedule, false),
+			gocron.NewTask(func() {
+				if err := bot.SendTomorrowPreview(conf.TelegramChatID); err != nil {
+					log.ErrorContext(ctx, "failed to send tomorrow preview", "error", err)
+				}
+			}),
+		)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create tomorrow preview job", "error", err, "schedule", conf.TomorrowPreviewSchedule)
+			return 1
+		}
+		jobs["tomorrow-preview"] = job
+	}
+
+	if conf.SummarySchedule != "" && conf.TelegramEnabled() {
+		job, err := scheduler.NewJob(
+			gocron.CronJob(conf.SummarySchedule, false),
+			gocron.NewTask(func() {
+				if err := bot.SendSummary(conf.TelegramChatID); err != nil {
+					log.ErrorContext(ctx, "failed to send summary", "error", err)
+				}
+			}),
+		)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create summary job", "error", err, "schedule", conf.SummarySchedule)
+			return 1
+		}
+		jobs["summary"] = job
+	}
+
+	if conf.EmailEnabled() {
+		email := internal.NewEmailChannel(conf.Email, conf.Template)
+		job, err := scheduler.NewJob(
+			gocron.CronJob(conf.EmailSchedule, false),
+			gocron.NewTask(func() {
+				if err := bot.SendDigest(email); err != nil {
+					log.ErrorContext(ctx, "failed to send email digest", "error", err)
+				}
+			}),
+		)
+		if err != nil {
+			log.ErrorContext(ctx, "failed to create email digest job", "error", err, "schedule", conf.EmailSchedule)
+			return 1
+		}
+		jobs["email-digest"] = job
 	}
 
 	scheduler.Start()
 
-	nextRun, err := job.NextRun()
-	if err != nil {
-		log.WarnContext(ctx, "failed to get next run time", "error", err)
-		log.InfoContext(ctx, "starting daemon", "schedule", conf.Schedule, "timezone", conf.Location)
-	} else {
-		log.InfoContext(ctx, "starting daemon", "schedule", conf.Schedule, "timezone", conf.Location, "next_run", nextRun)
+	for name, job := range jobs {
+		nextRun, err := job.NextRun()
+		if err != nil {
+			log.WarnContext(ctx, "failed to get next run time", "error", err, "job", name)
+			continue
+		}
+		log.InfoContext(ctx, "job scheduled", "job", name, "next_run", nextRun)
 	}
+	log.InfoContext(ctx, "starting daemon",
+		"schedule", conf.Schedule,
+		"timezone", conf.Location,
+		"active_profile", conf.ActiveProfile(clock.Now()).Name)
 	defer func() {
 		if err := scheduler.Shutdown(); err != nil {
 			log.ErrorContext(ctx, "failed to shutdown scheduler", "error", err)
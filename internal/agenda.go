@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+const weekDays = 7
+
+func (b *Bot) handleTomorrow(c tele.Context) error {
+	tomorrow := b.clock.Now().AddDate(0, 0, 1)
+	return b.sendAgenda(c.Chat().ID, true, tomorrow, tomorrow, "agenda.tomorrow", "agenda.tomorrow.empty")
+}
+
+func (b *Bot) handleWeek(c tele.Context) error {
+	now := b.clock.Now()
+	return b.sendAgenda(c.Chat().ID, true, now, now.AddDate(0, 0, weekDays-1), "agenda.week", "agenda.week.empty")
+}
+
+// SendTomorrowPreview sends the scheduled evening preview of tomorrow's tasks.
+// Nothing is sent if there are no tasks or tomorrow is a day off.
+func (b *Bot) SendTomorrowPreview(chatID int64) error {
+	tomorrow := b.clock.Now().AddDate(0, 0, 1)
+	if b.isDayOff(tomorrow) {
+		b.log.Debug("tomorrow is a day off, skipping preview")
+		return nil
+	}
+	return b.sendAgenda(chatID, false, tomorrow, tomorrow, "agenda.preview", "")
+}
+
+// sendAgenda sends tasks due between from and to under the titleKey message. An empty
+// emptyKey sends nothing when there are no tasks.
+func (b *Bot) sendAgenda(chatID int64, manualRequestMode bool, from, to time.Time, titleKey, emptyKey string) error {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	b.log.DebugContext(ctx, "sending agenda", "chat_id", chatID, "from", from.Format(time.DateOnly), "to", to.Format(time.DateOnly))
+
+	tasks, err := b.todoistClient.GetTasksLimit200(ctx, false)
+	if err != nil {
+		return fmt.Errorf("get tasks: %w", err)
+	}
+
+	opts, err := b.filterOptions(ctx, manualRequestMode, b.conf.ActiveProfile(from), b.conf.SortStrategy)
+	if err != nil {
+		return err
+	}
+	tasks = FilterAndSortTasksInRange(tasks, from, to, opts)
+
+	lang := b.lang(chatID)
+	var (
+		msgs   []string
+		format = FormatPlain
+	)
+	switch {
+	case len(tasks) != 0:
+		renderOpts := RenderOptions{Format: b.conf.MessageFormat, Links: b.conf.TaskLinks, Lang: lang}
+		if msgs, err = RenderAgendaMessage(lang.T(titleKey), tasks, from.Location(), renderOpts); err != nil {
+			return fmt.Errorf("render agenda message: %w", err)
+		}
+		format = b.conf.MessageFormat
+	case emptyKey != "":
+		msgs = []string{lang.T(emptyKey)}
+	default:
+		b.log.DebugContext(ctx, "no tasks to send")
+		return nil
+	}
+
+	if _, err := b.sendMessages(ctx, chatID, msgs, format, false); err != nil {
+		return err
+	}
+
+	b.log.DebugContext(ctx, "agenda sent successfully")
+	return nil
+}
@@ -8,108 +8,292 @@ import (
 	"time"
 
 	tele "gopkg.in/telebot.v3"
-)
 
-const (
-	defaultTimeout  = 10 * time.Second
-	defaultErrorMsg = "Something went wrong. Please try again later."
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
 )
 
+const defaultTimeout = 10 * time.Second
+
 type Bot struct {
 	conf Config
-	bot  *tele.Bot
+	// messenger is the Telegram chat, nil if Telegram is disabled.
+	messenger Messenger
+	// channels receive scheduled notifications next to the Telegram chat.
+	channels []Channel
 
 	todoistClient TodoistClient
+	store         StateStore
 	clock         Clock
 
 	log *slog.Logger
 }
 
-func NewBot(conf Config, todoistClient TodoistClient, clock Clock, log *slog.Logger) (*Bot, error) {
-	pref := tele.Settings{
-		Token:  conf.TelegramToken,
-		Poller: &tele.LongPoller{},
-	}
-
-	b, err := tele.NewBot(pref)
-	if err != nil {
-		return nil, fmt.Errorf("create bot: %w", err)
-	}
-
-	bot := &Bot{
+func NewBot(conf Config, messenger Messenger, todoistClient TodoistClient, store StateStore, clock Clock, log *slog.Logger, channels ...Channel) *Bot {
+	return &Bot{
 		conf:          conf,
-		bot:           b,
+		messenger:     messenger,
+		channels:      channels,
 		todoistClient: todoistClient,
+		store:         store,
 		clock:         clock,
 		log:           log,
 	}
-
-	bot.registerHandlers()
-
-	return bot, nil
 }
 
-func (b *Bot) Start(ctx context.Context) error {
+// Start handles Telegram commands until ctx is done.
+func (b *Bot) Start(ctx context.Context, tb *tele.Bot) error {
+	b.registerHandlers(tb)
+
 	go func() {
 		<-ctx.Done()
 		b.log.InfoContext(ctx, "stopping bot")
-		b.bot.Stop()
+		tb.Stop()
 	}()
 
 	b.log.InfoContext(ctx, "bot started")
-	b.bot.Start()
+	tb.Start()
 
 	return nil
 }
 
-func (b *Bot) registerHandlers() {
-	b.bot.Use(b.recover, b.handleError, b.chatIDMiddleware)
-	b.bot.Handle("/tasks", b.handleTasks)
+func (b *Bot) registerHandlers(tb *tele.Bot) {
+	tb.Use(b.recover, b.handleError, b.chatIDMiddleware)
+	tb.Handle("/tasks", b.handleTasks)
+	tb.Handle("/tomorrow", b.handleTomorrow)
+	tb.Handle("/week", b.handleWeek)
+	tb.Handle("/vacation", b.handleVacation)
+	tb.Handle("/language", b.handleLanguage)
 }
 
 func (b *Bot) handleTasks(c tele.Context) error {
-	return b.SendTasks(c.Chat().ID, true)
+	strategy := b.conf.SortStrategy
+	if c.Message().Payload != "" {
+		var err error
+		if strategy, err = ParseSortStrategy(c.Message().Payload); err != nil {
+			return c.Send(b.lang(c.Chat().ID).T("tasks.usage", joinStrategies()))
+		}
+	}
+	return b.sendTasks(c.Chat().ID, true, strategy)
 }
 
 func (b *Bot) SendTasks(chatID int64, manualRequestMode bool) error {
+	return b.sendTasks(chatID, manualRequestMode, b.conf.SortStrategy)
+}
+
+func (b *Bot) sendTasks(chatID int64, manualRequestMode bool, strategy SortStrategy) error {
 	ctx, cancel := b.context()
 	defer cancel()
 
 	b.log.DebugContext(ctx, "received /tasks command", "chat_id", chatID)
 
-	tasks, err := b.todoistClient.GetTasksLimit200(ctx, false)
+	now := b.clock.Now()
+	lang := b.lang(chatID)
+	profile := b.conf.ActiveProfile(now)
+	if !manualRequestMode && profile.QuietHours.Contains(now) {
+		b.log.DebugContext(ctx, "quiet hours, skipping notification", "profile", profile.Name)
+		return nil
+	}
+	dayOff := !manualRequestMode && b.isDayOff(now)
+	if dayOff && b.conf.DaysOffMode == DaysOffSkip {
+		b.log.DebugContext(ctx, "day off, skipping notification")
+		return nil
+	}
+
+	openTasks, err := b.todoistClient.GetTasksLimit200(ctx, false)
 	if err != nil {
 		return fmt.Errorf("get tasks: %w", err)
 	}
 
-	ignoreProjects := b.conf.IgnoreProjectIDs
-	if manualRequestMode {
-		ignoreProjects = nil
+	opts, err := b.filterOptions(ctx, manualRequestMode, profile, strategy)
+	if err != nil {
+		return err
+	}
+	if dayOff {
+		opts.MinPriority = P1
+	}
+	tasks := FilterAndSortTasks(openTasks, now, opts)
+	decisions := ExplainFilter(openTasks, now, opts)
+
+	var (
+		counts map[string]NotifiedTask
+		sent   *SentTasks
+	)
+	b.store.View(func(state *State) {
+		counts = state.Notified
+		if !manualRequestMode {
+			counts = NextNotificationCounts(state.Notified, tasks, openTasks)
+			if prev, ok := state.Sent[chatID]; ok && prev.SameDay(now) {
+				sent = &prev
+			}
+		}
+	})
+	if b.conf.SkipUnchanged && sent != nil && sent.Same(tasks) {
+		b.log.DebugContext(ctx, "tasks unchanged since previous notification, skipping", "since", sent.At)
+		return nil
 	}
-	tasks = FilterAndSortTasks(tasks, b.clock.Now(), !manualRequestMode, ignoreProjects)
+	nagged := naggedTasks(counts, tasks, b.conf.NagThreshold)
 
-	var msg string
+	renderOpts := RenderOptions{
+		Nagged:    nagged,
+		Escalated: escalatedTasks(decisions),
+		Habits:    b.conf.RecurringMode == RecurringHabits,
+		GroupBy:   b.conf.GroupBy,
+		Catalog:   opts.Catalog,
+		Format:    b.conf.MessageFormat,
+		Links:     b.conf.TaskLinks,
+		Template:  b.conf.Template,
+		Lang:      lang,
+		Fields:    b.conf.TaskFields,
+	}
+	if b.conf.NotifyMode == NotifyDiff && sent != nil {
+		renderOpts.Changes = sent.Changes(openTasks)
+	}
+	if b.conf.EndOfDay > 0 {
+		// estimate the whole day, including tasks not revealed yet but not the ones due later
+		dayOpts := opts
+		dayOpts.FilterByTime = false
+		workload := EstimateWorkload(dueToday(FilterAndSortTasks(openTasks, now, dayOpts), now), now, b.conf.EndOfDay)
+		renderOpts.Workload = &workload
+	}
+
+	if !manualRequestMode && len(tasks) != 0 && len(b.channels) != 0 {
+		digest := NewDigest(tasks, now, renderOpts, newRunID())
+		digest.Decisions = decisions
+		wait := b.notifyChannels(ctx, digest)
+		defer wait()
+	}
+	if b.messenger == nil {
+		// Telegram is disabled, the channels are the only targets
+		return b.saveNotified(chatID, counts, tasks, now)
+	}
+
+	var (
+		msgs   []string
+		format = FormatPlain
+	)
 	switch {
-	case len(tasks) != 0:
-		msg, err = RenderTasksMessage(tasks)
+	case len(tasks) != 0 || (renderOpts.Changes != nil && len(renderOpts.Changes.Completed) != 0):
+		msgs, err = RenderTasksMessage(tasks, now, renderOpts)
 		if err != nil {
 			return fmt.Errorf("render tasks message: %w", err)
 		}
-	case len(tasks) == 0 && manualRequestMode:
-		msg = "No tasks for today! 🎉"
-	case len(tasks) == 0 && !manualRequestMode:
+		format = b.conf.MessageFormat
+	case len(tasks) == 0 && (manualRequestMode || b.hasLiveMessage(chatID, now)):
+		// the live message still lists the tasks done since, replace them
+		msgs = []string{lang.T("tasks.empty")}
+	default:
 		b.log.DebugContext(ctx, "no tasks to send")
-		return nil
+		return b.saveSent(chatID, tasks, now)
 	}
 
-	if _, err := b.bot.Send(&tele.Chat{ID: chatID}, msg); err != nil {
-		return fmt.Errorf("send message: %w", err)
+	if b.conf.LiveMessage && !manualRequestMode {
+		err = b.updateLiveMessage(ctx, chatID, now, msgs, format, dayOff)
+	} else {
+		_, err = b.sendMessages(ctx, chatID, msgs, format, dayOff)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !manualRequestMode {
+		if err := b.saveNotified(chatID, counts, tasks, now); err != nil {
+			return err
+		}
+
+		if b.conf.NagAlert && len(nagged) > 0 && !dayOff {
+			alert := lang.N("nag.alert", len(nagged), b.conf.NagThreshold)
+			if _, err := b.messenger.Send(ctx, chatID, Message{Text: alert, Format: FormatPlain}); err != nil {
+				return fmt.Errorf("send nag alert: %w", err)
+			}
+		}
 	}
 
 	b.log.DebugContext(ctx, "tasks sent successfully")
 	return nil
 }
 
+// saveNotified persists the notification counts and the tasks of a scheduled notification.
+func (b *Bot) saveNotified(chatID int64, counts map[string]NotifiedTask, tasks []todoist.Task, now time.Time) error {
+	if err := b.store.Update(func(state *State) error {
+		state.Notified = counts
+		return nil
+	}); err != nil {
+		return fmt.Errorf("save notification counts: %w", err)
+	}
+	return b.saveSent(chatID, tasks, now)
+}
+
+// saveSent remembers the tasks of a scheduled notification, so that the next one can be compared with it.
+func (b *Bot) saveSent(chatID int64, tasks []todoist.Task, now time.Time) error {
+	if err := b.store.Update(func(state *State) error {
+		if state.Sent == nil {
+			state.Sent = make(map[int64]SentTasks)
+		}
+		state.Sent[chatID] = NewSentTasks(tasks, now)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("save sent tasks: %w", err)
+	}
+	return nil
+}
+
+// sendMessages sends message chunks in order and returns their IDs. Only the first chunk triggers
+// a notification, unless silent is set.
+func (b *Bot) sendMessages(ctx context.Context, chatID int64, msgs []string, format Format, silent bool) ([]int, error) {
+	ids := make([]int, 0, len(msgs))
+	for i, msg := range msgs {
+		id, err := b.messenger.Send(ctx, chatID, Message{Text: msg, Format: format, Silent: silent || i > 0})
+		if err != nil {
+			return nil, fmt.Errorf("send message %d/%d: %w", i+1, len(msgs), err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// filterOptions builds the filter for a request. Manual requests only apply sorting and
+// deadline escalation, scheduled ones apply the profile and configured rules as well.
+func (b *Bot) filterOptions(ctx context.Context, manualRequestMode bool, profile Profile, strategy SortStrategy) (FilterOptions, error) {
+	var opts FilterOptions
+	if !manualRequestMode {
+		opts = FilterOptions{
+			FilterByTime:     profile.FilterByTime,
+			IgnoreProjectIDs: profile.IgnoreProjectIDs,
+			Labels:           b.conf.LabelRule,
+			Sections:         b.conf.SectionRule,
+			Projects:         b.conf.ProjectRule,
+		}
+	}
+	opts.Sort = strategy
+	opts.DeadlineWindow = b.conf.DeadlineWindow
+	opts.ExcludeRecurring = b.conf.RecurringMode == RecurringExclude
+	opts.RecurringFrom = b.conf.RecurringFrom
+
+	// channels group tasks by project
+	if opts.NeedsCatalog() || b.conf.GroupBy != GroupNone || b.conf.TaskFields.Project || (!manualRequestMode && len(b.channels) != 0) {
+		var err error
+		if opts.Catalog, err = b.fetchCatalog(ctx); err != nil {
+			return FilterOptions{}, err
+		}
+	}
+
+	return opts, nil
+}
+
+func (b *Bot) fetchCatalog(ctx context.Context) (*Catalog, error) {
+	projects, err := b.todoistClient.GetProjects(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get projects: %w", err)
+	}
+
+	sections, err := b.todoistClient.GetSections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get sections: %w", err)
+	}
+
+	return NewCatalog(projects, sections), nil
+}
+
 func (b *Bot) context() (context.Context, func()) {
 	return context.WithTimeout(context.Background(), defaultTimeout)
 }
@@ -133,7 +317,7 @@ func (b *Bot) chatIDMiddleware(next tele.HandlerFunc) tele.HandlerFunc {
 				"chat_id", c.Chat().ID,
 				"allowed_chat_id", b.conf.TelegramChatID,
 			)
-			return c.Send("Unauthorized")
+			return c.Send(b.conf.Language.T("error.unauthorized"))
 		}
 		return next(c)
 	}
@@ -151,7 +335,7 @@ func (b *Bot) handleError(next tele.HandlerFunc) tele.HandlerFunc {
 				args = append(args, "command", strings.Split(c.Message().Text, " ")[0])
 			}
 			b.log.Error("error occurred", args...)
-			return c.Send(defaultErrorMsg)
+			return c.Send(b.lang(c.Chat().ID).T("error.generic"))
 		}
 		return err
 	}
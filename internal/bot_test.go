@@ -0,0 +1,442 @@
+package internal_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+const testChatID = 42
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+type fakeTodoist struct {
+	open      []todoist.Task
+	completed []todoist.Task
+}
+
+func (f *fakeTodoist) GetTasksLimit200(context.Context, bool) ([]todoist.Task, error) {
+	return f.open, nil
+}
+
+func (f *fakeTodoist) GetCompletedTasks(context.Context, time.Time, time.Time) ([]todoist.Task, error) {
+	return f.completed, nil
+}
+
+func (f *fakeTodoist) GetProjects(context.Context) ([]todoist.Project, error) {
+	return nil, nil
+}
+
+func (f *fakeTodoist) GetSections(context.Context) ([]todoist.Section, error) {
+	return nil, nil
+}
+
+type fakeChannel struct {
+	digests []internal.Digest
+}
+
+func (c *fakeChannel) Name() string {
+	return "fake"
+}
+
+func (c *fakeChannel) Send(_ context.Context, digest internal.Digest) error {
+	c.digests = append(c.digests, digest)
+	return nil
+}
+
+// blockingChannel waits for wait before it accepts a digest, and closes done when it did.
+type blockingChannel struct {
+	wait <-chan struct{}
+	done chan struct{}
+	// timeout is the time the delivery had left when it started.
+	timeout time.Duration
+}
+
+func (c *blockingChannel) Name() string {
+	return "blocking"
+}
+
+func (c *blockingChannel) Send(ctx context.Context, _ internal.Digest) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		c.timeout = time.Until(deadline)
+	}
+	select {
+	case <-c.wait:
+		close(c.done)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+type botFixture struct {
+	bot       *internal.Bot
+	messenger *internal.MemoryMessenger
+	todoist   *fakeTodoist
+	clock     *fakeClock
+}
+
+func newBotFixture(t *testing.T, configure func(conf *internal.Config), channels ...internal.Channel) *botFixture {
+	t.Helper()
+
+	conf := internal.Config{
+		TelegramChatID: testChatID,
+		SortStrategy:   internal.SortByPriority,
+		RecurringMode:  internal.RecurringShow,
+		GroupBy:        internal.GroupNone,
+		MessageFormat:  internal.FormatPlain,
+		NotifyMode:     internal.NotifyFull,
+		DaysOffMode:    internal.DaysOffSkip,
+	}
+	if configure != nil {
+		configure(&conf)
+	}
+
+	store, err := internal.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := &botFixture{
+		messenger: internal.NewMemoryMessenger(),
+		todoist:   &fakeTodoist{},
+		clock:     &fakeClock{now: time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)},
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	res.bot = internal.NewBot(conf, res.messenger, res.todoist, store, res.clock, log, channels...)
+	return res
+}
+
+func (f *botFixture) send(t *testing.T) []internal.MemoryMessage {
+	t.Helper()
+	if err := f.bot.SendTasks(testChatID, false); err != nil {
+		t.Fatal(err)
+	}
+	return f.messenger.Messages(testChatID)
+}
+
+func today(id, content string, priority int) todoist.Task {
+	return todoist.Task{ID: id, Content: content, Priority: priority, Due: &todoist.TaskDue{Date: "2026-01-12"}}
+}
+
+func TestBot_SendTasks(t *testing.T) {
+	f := newBotFixture(t, nil)
+	f.todoist.open = []todoist.Task{today("1", "report", 4), today("2", "laundry", 1)}
+
+	msgs := f.send(t)
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message, got %+v", msgs)
+	}
+	if want := "Uncompleted tasks for today:\n- 🔴 report\n"; msgs[0].Text != want || msgs[0].Silent {
+		t.Errorf("expected loud message %q, got %+v", want, msgs[0])
+	}
+
+	f.todoist.open = nil
+	if msgs := f.send(t); len(msgs) != 1 {
+		t.Errorf("expected no message without tasks, got %+v", msgs)
+	}
+}
+
+func TestBot_SendTasks_Diff(t *testing.T) {
+	f := newBotFixture(t, func(conf *internal.Config) {
+		conf.NotifyMode = internal.NotifyDiff
+		conf.SkipUnchanged = true
+	})
+	f.todoist.open = []todoist.Task{today("1", "report", 4), today("2", "call", 4)}
+	f.send(t)
+
+	f.clock.now = f.clock.now.Add(time.Hour)
+	if msgs := f.send(t); len(msgs) != 1 {
+		t.Fatalf("expected unchanged tasks to be skipped, got %+v", msgs)
+	}
+
+	f.clock.now = f.clock.now.Add(time.Hour)
+	f.todoist.open = []todoist.Task{today("1", "report", 4), today("3", "review", 4)}
+	msgs := f.send(t)
+	if len(msgs) != 2 {
+		t.Fatalf("expected a second message, got %+v", msgs)
+	}
+	want := "New since 10:00:\n- 🔴 review\n\n" +
+		"Still pending:\n- 🔴 report\n\n" +
+		"Completed ✅\n- 🔴 call\n"
+	if msgs[1].Text != want {
+		t.Errorf("expected message %q, got %q", want, msgs[1].Text)
+	}
+}
+
+func TestBot_SendTasks_LiveMessage(t *testing.T) {
+	f := newBotFixture(t, func(conf *internal.Config) {
+		conf.LiveMessage = true
+	})
+	f.todoist.open = []todoist.Task{today("1", "report", 4), today("2", "call", 4)}
+	msgs := f.send(t)
+	if len(msgs) != 1 || !msgs[0].Pinned {
+		t.Fatalf("expected a pinned message, got %+v", msgs)
+	}
+
+	f.clock.now = f.clock.now.Add(time.Hour)
+	f.todoist.open = f.todoist.open[:1]
+	msgs = f.send(t)
+	if len(msgs) != 1 || msgs[0].Edits != 1 || msgs[0].Text != "Uncompleted tasks for today:\n- 🔴 report\n" {
+		t.Fatalf("expected the message to be edited, got %+v", msgs)
+	}
+
+	f.clock.now = f.clock.now.Add(time.Hour)
+	f.todoist.open = nil
+	msgs = f.send(t)
+	if len(msgs) != 1 || msgs[0].Text != "No tasks for today! 🎉" {
+		t.Fatalf("expected the message to be emptied, got %+v", msgs)
+	}
+
+	f.clock.now = f.clock.now.AddDate(0, 0, 1)
+	f.todoist.open = []todoist.Task{{ID: "3", Content: "gym", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-13"}}}
+	msgs = f.send(t)
+	if len(msgs) != 2 || msgs[0].Pinned || !msgs[1].Pinned {
+		t.Fatalf("expected a new pinned message on a new day, got %+v", msgs)
+	}
+
+	if err := f.messenger.Delete(context.Background(), testChatID, msgs[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	f.todoist.open = append(f.todoist.open, todoist.Task{ID: "4", Content: "late", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-13"}})
+	msgs = f.send(t)
+	if len(msgs) != 2 || !msgs[1].Pinned {
+		t.Fatalf("expected a new message when the old one is gone, got %+v", msgs)
+	}
+}
+
+func TestBot_SendTasks_LiveMessageChunks(t *testing.T) {
+	f := newBotFixture(t, func(conf *internal.Config) {
+		conf.LiveMessage = true
+	})
+	many := make([]todoist.Task, 0, 100)
+	for i := range 100 {
+		many = append(many, today(strconv.Itoa(i), fmt.Sprintf("task #%03d with a long enough name to fill a message", i), 4))
+	}
+
+	f.todoist.open = many
+	msgs := f.send(t)
+	if len(msgs) != 2 || !msgs[0].Pinned {
+		t.Fatalf("expected 2 chunks with the first one pinned, got %d messages", len(msgs))
+	}
+	first := msgs[0].ID
+
+	f.clock.now = f.clock.now.Add(time.Hour)
+	f.todoist.open = many[:1]
+	msgs = f.send(t)
+	if len(msgs) != 1 || msgs[0].ID != first || msgs[0].Edits != 1 || !msgs[0].Pinned {
+		t.Fatalf("expected the first chunk to be edited and the second one deleted, got %+v", msgs)
+	}
+
+	f.clock.now = f.clock.now.Add(time.Hour)
+	f.todoist.open = many
+	msgs = f.send(t)
+	if len(msgs) != 2 || msgs[0].ID != first || !msgs[1].Silent {
+		t.Fatalf("expected the first chunk to be edited and a silent second one sent, got %d messages", len(msgs))
+	}
+
+	// the second chunk can no longer be edited
+	if err := f.messenger.Delete(context.Background(), testChatID, msgs[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	f.clock.now = f.clock.now.Add(time.Hour)
+	f.todoist.open = many[1:]
+	msgs = f.send(t)
+	if len(msgs) != 2 || msgs[0].ID != first || !msgs[0].Pinned {
+		t.Fatalf("expected the first chunk to be kept and the second one replaced, got %d messages", len(msgs))
+	}
+
+	// the first chunk can no longer be edited
+	if err := f.messenger.Delete(context.Background(), testChatID, first); err != nil {
+		t.Fatal(err)
+	}
+	f.clock.now = f.clock.now.Add(time.Hour)
+	f.todoist.open = many
+	msgs = f.send(t)
+	if len(msgs) != 2 || msgs[0].ID == first || !msgs[0].Pinned || msgs[1].Pinned {
+		t.Fatalf("expected both chunks to be replaced in order, got %d messages", len(msgs))
+	}
+}
+
+func TestBot_SendTasks_ProfileQuietHours(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`{"profiles": [{"name": "workday", "weekdays": ["mon"]}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f := newBotFixture(t, func(conf *internal.Config) {
+		conf.QuietHours = &internal.QuietHours{From: 8 * time.Hour, To: 11 * time.Hour}
+		var err error
+		if conf.Profiles, err = internal.LoadProfiles(path, conf.DefaultProfile()); err != nil {
+			t.Fatal(err)
+		}
+	})
+	f.todoist.open = []todoist.Task{today("1", "report", 4)}
+
+	// Monday 10:00, the workday profile inherits the quiet hours
+	if msgs := f.send(t); len(msgs) != 0 {
+		t.Fatalf("expected no message in the inherited quiet hours, got %+v", msgs)
+	}
+
+	f.clock.now = f.clock.now.Add(time.Hour)
+	if msgs := f.send(t); len(msgs) != 1 {
+		t.Errorf("expected a message after the quiet hours, got %+v", msgs)
+	}
+}
+
+func TestBot_SendTasks_Channels(t *testing.T) {
+	channel := &fakeChannel{}
+	todo := &fakeTodoist{open: []todoist.Task{today("1", "report", 4)}}
+	clock := &fakeClock{now: time.Date(2026, 1, 12, 10, 0, 0, 0, time.UTC)}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store, err := internal.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Telegram disabled, only the channel is notified
+	bot := internal.NewBot(internal.Config{SortStrategy: internal.SortByPriority, NagThreshold: 1}, nil, todo, store, clock, log, channel)
+
+	for range 2 {
+		if err := bot.SendTasks(0, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bot.SendTasks(0, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(channel.digests) != 2 {
+		t.Fatalf("expected 2 scheduled digests, got %d", len(channel.digests))
+	}
+	first, second := channel.digests[0], channel.digests[1]
+	if first.RunID == "" || first.RunID == second.RunID {
+		t.Errorf("expected unique run IDs, got %q and %q", first.RunID, second.RunID)
+	}
+	if len(first.Groups) != 1 || first.Groups[0].Tasks[0].URL != "https://app.todoist.com/app/task/1" {
+		t.Errorf("expected tasks grouped with web links, got %+v", first.Groups)
+	}
+	if len(first.Decisions) != 1 || first.Decisions[0].Reason != internal.FilterIncluded {
+		t.Errorf("expected the filter decision of the task, got %+v", first.Decisions)
+	}
+	if len(second.Pending) != 1 {
+		t.Errorf("expected the task to be pending after the threshold, got %+v", second.Pending)
+	}
+}
+
+func TestBot_SendSummary(t *testing.T) {
+	f := newBotFixture(t, nil)
+	f.todoist.open = []todoist.Task{today("1", "report", 4)}
+	gym := today("3", "gym", 1)
+	gym.Due = &todoist.TaskDue{Date: "2026-01-14", IsRecurring: true}
+	f.todoist.completed = []todoist.Task{
+		today("2", "call", 3),
+		gym,
+		{ID: "4", Content: "tomorrow's task done early", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-13"}},
+		{ID: "5", Content: "undated", Priority: 4},
+	}
+
+	if err := f.bot.SendSummary(testChatID); err != nil {
+		t.Fatal(err)
+	}
+	msgs := f.messenger.Messages(testChatID)
+	if len(msgs) != 1 || msgs[0].Photo == nil {
+		t.Fatalf("expected a summary photo, got %+v", msgs)
+	}
+	if want := "📊 Done today: 2 of 3"; !strings.HasPrefix(msgs[0].Text, want) {
+		t.Errorf("expected caption to start with %q, got %q", want, msgs[0].Text)
+	}
+}
+
+func TestBot_SendTasks_SlowChannel(t *testing.T) {
+	ready := make(chan struct{})
+	close(ready)
+	fast := &blockingChannel{wait: ready, done: make(chan struct{})}
+	// the slow channel is only done after the fast one, which would never happen in turn
+	slow := &blockingChannel{wait: fast.done, done: make(chan struct{})}
+	f := newBotFixture(t, nil, slow, fast)
+	f.todoist.open = []todoist.Task{today("1", "report", 4)}
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- f.bot.SendTasks(testChatID, false)
+	}()
+	select {
+	case err := <-sent:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the slow channel not to hold up the fast one")
+	}
+
+	select {
+	case <-slow.done:
+	default:
+		t.Error("expected the slow channel to be notified")
+	}
+	// webhooks need room for their retries, more than a single request
+	if slow.timeout <= 10*time.Second {
+		t.Errorf("expected a channel timeout above the request timeout, got %s", slow.timeout)
+	}
+}
+
+func TestBot_SendDigest(t *testing.T) {
+	f := newBotFixture(t, nil)
+	channel := &fakeChannel{}
+	// hidden from scheduled notifications until 9pm
+	later := today("2", "call", 3)
+	later.Labels = []string{"9pm"}
+	f.todoist.open = []todoist.Task{today("1", "report", 4), later}
+
+	if err := f.bot.SendDigest(channel); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(channel.digests) != 1 {
+		t.Fatalf("expected a digest, got %d", len(channel.digests))
+	}
+	if got := channel.digests[0].Counts.Total; got != 2 {
+		t.Errorf("expected the whole day in the digest, got %d tasks", got)
+	}
+	if msgs := f.messenger.Messages(testChatID); len(msgs) != 0 {
+		t.Errorf("expected no Telegram message, got %+v", msgs)
+	}
+}
+
+func TestBot_SendTasks_WorkloadIgnoresEscalated(t *testing.T) {
+	f := newBotFixture(t, func(conf *internal.Config) {
+		conf.EndOfDay = 22 * time.Hour
+		conf.DeadlineWindow = 72 * time.Hour
+	})
+	report := today("1", "report", 4)
+	report.Duration = &todoist.TaskDuration{Amount: 60, Unit: "minute"}
+	// shown today because of its deadline, but planned in two days
+	thesis := todoist.Task{ID: "2", Content: "thesis", Priority: 4,
+		Due:      &todoist.TaskDue{Date: "2026-01-14"},
+		Deadline: &todoist.TaskDeadline{Date: "2026-01-14"},
+		Duration: &todoist.TaskDuration{Amount: 720, Unit: "minute"}}
+	f.todoist.open = []todoist.Task{report, thesis}
+
+	msgs := f.send(t)
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Text, "thesis") {
+		t.Fatalf("expected the escalated task to be listed, got %+v", msgs)
+	}
+	if strings.Contains(msgs[0].Text, "Overbooked") {
+		t.Errorf("expected only today's work to be estimated, got %q", msgs[0].Text)
+	}
+}
@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+type DaysOffMode string
+
+const (
+	// DaysOffSkip suppresses scheduled notifications on days off.
+	DaysOffSkip DaysOffMode = "skip"
+	// DaysOffDowngrade sends only P1 tasks, silently, on days off.
+	DaysOffDowngrade DaysOffMode = "downgrade"
+)
+
+// DateRange is an inclusive range of dates in YYYY-MM-DD format.
+type DateRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func ParseDateRange(from, to string) (DateRange, error) {
+	f, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return DateRange{}, fmt.Errorf("parse date %q: %w", from, err)
+	}
+	t, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return DateRange{}, fmt.Errorf("parse date %q: %w", to, err)
+	}
+	if t.Before(f) {
+		return DateRange{}, fmt.Errorf("end date %s is before start date %s", to, from)
+	}
+	return DateRange{From: from, To: to}, nil
+}
+
+func (r DateRange) Contains(t time.Time) bool {
+	date := t.Format(time.DateOnly)
+	return date >= r.From && date <= r.To
+}
+
+func (r DateRange) String() string {
+	if r.From == r.To {
+		return r.From
+	}
+	return r.From + " – " + r.To
+}
+
+// ParseDaysOff parses a comma separated list of dates and date ranges,
+// e.g. "2026-01-01,2026-12-24..2026-12-26".
+func ParseDaysOff(s string) ([]DateRange, error) {
+	var res []DateRange
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(item, "..")
+		if !ok {
+			to = from
+		}
+		r, err := ParseDateRange(strings.TrimSpace(from), strings.TrimSpace(to))
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+// LoadICS reads the events of an iCalendar file as days off. Only DTSTART/DTEND are
+// considered; recurrence rules are not expanded.
+func LoadICS(path string) ([]DateRange, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("read calendar: %w", err)
+	}
+	return ParseICS(data)
+}
+
+func ParseICS(data []byte) ([]DateRange, error) {
+	var (
+		res        []DateRange
+		inEvent    bool
+		start, end string
+		endIsDate  bool
+	)
+
+	for _, line := range unfoldICS(data) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(name, ";")
+
+		switch strings.ToUpper(name) {
+		case "BEGIN":
+			if strings.EqualFold(value, "VEVENT") {
+				inEvent, start, end, endIsDate = true, "", "", false
+			}
+		case "DTSTART":
+			if inEvent {
+				start = value
+			}
+		case "DTEND":
+			if inEvent {
+				end = value
+				endIsDate = strings.Contains(strings.ToUpper(params), "VALUE=DATE") || len(value) == len("20060102")
+			}
+		case "END":
+			if !strings.EqualFold(value, "VEVENT") || !inEvent {
+				continue
+			}
+			inEvent = false
+			r, err := icsEventRange(start, end, endIsDate)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
+
+// icsEventRange converts event boundaries to an inclusive date range.
+// All-day events have an exclusive DTEND, so the last day is the one before it.
+func icsEventRange(start, end string, endIsDate bool) (DateRange, error) {
+	from, err := parseICSDate(start)
+	if err != nil {
+		return DateRange{}, err
+	}
+	to := from
+	if end != "" {
+		if to, err = parseICSDate(end); err != nil {
+			return DateRange{}, err
+		}
+		if endIsDate && to.After(from) {
+			to = to.AddDate(0, 0, -1)
+		}
+	}
+	return DateRange{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly)}, nil
+}
+
+func parseICSDate(s string) (time.Time, error) {
+	if len(s) < len("20060102") {
+		return time.Time{}, fmt.Errorf("invalid calendar date %q", s)
+	}
+	t, err := time.Parse("20060102", s[:len("20060102")])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid calendar date %q: %w", s, err)
+	}
+	return t, nil
+}
+
+// unfoldICS splits iCalendar content into logical lines, joining folded continuation lines.
+func unfoldICS(data []byte) []string {
+	var res []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(res) > 0 {
+			res[len(res)-1] += line[1:]
+			continue
+		}
+		res = append(res, line)
+	}
+	return res
+}
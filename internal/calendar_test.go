@@ -0,0 +1,67 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+)
+
+func TestParseICS(t *testing.T) {
+	data := []byte("BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Christmas\r\n" +
+		"DTSTART;VALUE=DATE:20261224\r\n" +
+		"DTEND;VALUE=DATE:20261227\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"SUMMARY:Long meeting with a folded\r\n" +
+		"  description\r\n" +
+		"DTSTART:20260310T090000Z\r\n" +
+		"DTEND:20260310T170000Z\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"DTSTART;VALUE=DATE:20260101\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n")
+
+	ranges, err := internal.ParseICS(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []internal.DateRange{
+		{From: "2026-12-24", To: "2026-12-26"},
+		{From: "2026-03-10", To: "2026-03-10"},
+		{From: "2026-01-01", To: "2026-01-01"},
+	}
+	if len(ranges) != len(expected) {
+		t.Fatalf("expected %d ranges, got %d: %v", len(expected), len(ranges), ranges)
+	}
+	for i, r := range expected {
+		if ranges[i] != r {
+			t.Errorf("expected range[%d] to be %v, got %v", i, r, ranges[i])
+		}
+	}
+
+	if !ranges[0].Contains(time.Date(2026, 12, 26, 23, 0, 0, 0, time.UTC)) {
+		t.Error("expected last day of all-day event to be included")
+	}
+	if ranges[0].Contains(time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Error("expected exclusive DTEND day to be excluded")
+	}
+}
+
+func TestParseDaysOff(t *testing.T) {
+	ranges, err := internal.ParseDaysOff("2026-01-01, 2026-05-01..2026-05-03,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ranges) != 2 || ranges[1] != (internal.DateRange{From: "2026-05-01", To: "2026-05-03"}) {
+		t.Errorf("unexpected ranges %v", ranges)
+	}
+
+	if _, err := internal.ParseDaysOff("2026-05-03..2026-05-01"); err == nil {
+		t.Error("expected error for reversed range")
+	}
+}
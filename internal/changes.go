@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+// NotifyMode selects what scheduled notifications show.
+type NotifyMode string
+
+const (
+	// NotifyFull lists all of today's tasks in every notification.
+	NotifyFull NotifyMode = "full"
+	// NotifyDiff compares the tasks with the previous notification of the day and lists new,
+	// still pending and completed tasks.
+	NotifyDiff NotifyMode = "diff"
+)
+
+// SentTasks is a snapshot of the tasks in the last scheduled notification of a chat.
+type SentTasks struct {
+	At    time.Time  `json:"at"`
+	Tasks []SentTask `json:"tasks"`
+}
+
+// SentTask keeps enough of a task to list it as completed once it is gone from Todoist.
+type SentTask struct {
+	ID       string `json:"id"`
+	Content  string `json:"content"`
+	Priority int    `json:"priority"`
+}
+
+// TaskChanges describes the previous notification for RenderOptions.
+type TaskChanges struct {
+	// Since is when the previous notification was sent.
+	Since time.Time
+	// Previous are the IDs of tasks in the previous notification.
+	Previous map[string]bool
+	// Completed are tasks of the previous notification that are no longer open.
+	Completed []todoist.Task
+}
+
+func NewSentTasks(tasks []todoist.Task, at time.Time) SentTasks {
+	res := SentTasks{At: at, Tasks: make([]SentTask, len(tasks))}
+	for i, t := range tasks {
+		res.Tasks[i] = SentTask{ID: t.ID, Content: t.Content, Priority: t.Priority}
+	}
+	return res
+}
+
+// SameDay reports whether the snapshot was taken on the day of t.
+func (s SentTasks) SameDay(t time.Time) bool {
+	return s.At.In(t.Location()).Format(time.DateOnly) == t.Format(time.DateOnly)
+}
+
+// Same reports whether tasks are exactly the tasks of the snapshot, in any order.
+func (s SentTasks) Same(tasks []todoist.Task) bool {
+	if len(tasks) != len(s.Tasks) {
+		return false
+	}
+	ids := s.ids()
+	for _, t := range tasks {
+		if !ids[t.ID] {
+			return false
+		}
+	}
+	return true
+}
+
+// Changes compares the snapshot with the currently open tasks. Tasks of the snapshot that are
+// not open anymore were completed (or deleted), tasks just filtered out, e.g. rescheduled, are not listed.
+func (s SentTasks) Changes(open []todoist.Task) *TaskChanges {
+	openIDs := make(map[string]bool, len(open))
+	for _, t := range open {
+		openIDs[t.ID] = true
+	}
+
+	res := &TaskChanges{Since: s.At, Previous: s.ids()}
+	for _, t := range s.Tasks {
+		if !openIDs[t.ID] {
+			res.Completed = append(res.Completed, todoist.Task{ID: t.ID, Content: t.Content, Priority: t.Priority})
+		}
+	}
+	return res
+}
+
+func (s SentTasks) ids() map[string]bool {
+	res := make(map[string]bool, len(s.Tasks))
+	for _, t := range s.Tasks {
+		res[t.ID] = true
+	}
+	return res
+}
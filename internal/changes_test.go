@@ -0,0 +1,104 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestSentTasks(t *testing.T) {
+	at := time.Date(2026, 1, 11, 14, 0, 0, 0, time.UTC)
+	sent := internal.NewSentTasks([]todoist.Task{
+		{ID: "1", Content: "report", Priority: 4},
+		{ID: "2", Content: "call", Priority: 1},
+	}, at)
+
+	if !sent.SameDay(at.Add(9*time.Hour)) || sent.SameDay(at.Add(10*time.Hour)) {
+		t.Error("expected snapshot to be valid until midnight only")
+	}
+	if !sent.Same([]todoist.Task{{ID: "2"}, {ID: "1"}}) {
+		t.Error("expected same tasks in a different order to be unchanged")
+	}
+	if sent.Same([]todoist.Task{{ID: "1"}, {ID: "3"}}) || sent.Same([]todoist.Task{{ID: "1"}}) {
+		t.Error("expected different tasks to be changed")
+	}
+
+	changes := sent.Changes([]todoist.Task{{ID: "1"}, {ID: "3"}})
+	if !changes.Since.Equal(at) || !changes.Previous["1"] || !changes.Previous["2"] {
+		t.Errorf("unexpected changes %+v", changes)
+	}
+	if len(changes.Completed) != 1 || changes.Completed[0].Content != "call" {
+		t.Errorf("expected call to be completed, got %+v", changes.Completed)
+	}
+}
+
+func TestRenderTasksMessage_Changes(t *testing.T) {
+	now := time.Date(2026, 1, 11, 15, 0, 0, 0, time.UTC)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "report", Priority: 4},
+		{ID: "3", Content: "review", Priority: 3},
+		{ID: "4", Content: "gym", Priority: 1, Due: &todoist.TaskDue{Date: "2026-01-11", IsRecurring: true}},
+	}
+	changes := &internal.TaskChanges{
+		Since:     now.Add(-time.Hour),
+		Previous:  map[string]bool{"1": true, "2": true},
+		Completed: []todoist.Task{{ID: "2", Content: "call", Priority: 1}},
+	}
+
+	tests := []struct {
+		name     string
+		tasks    []todoist.Task
+		expected string
+	}{
+		{
+			name:  "all",
+			tasks: tasks,
+			expected: "New since 14:00:\n- 🟠 review\n\n" +
+				"Still pending:\n- 🔴 report\n\n" +
+				"Completed ✅\n- ⚪ call\n\n" +
+				"Habits: gym\n",
+		},
+		{
+			name:     "completed only",
+			tasks:    nil,
+			expected: "Completed ✅\n- ⚪ call\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := singleMessage(internal.RenderTasksMessage(tt.tasks, now, internal.RenderOptions{Habits: true, Changes: changes}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, msg)
+			}
+		})
+	}
+}
+
+func TestRenderTasksMessage_ChangesGrouped(t *testing.T) {
+	now := time.Date(2026, 1, 11, 15, 0, 0, 0, time.UTC)
+	catalog := internal.NewCatalog(
+		[]todoist.Project{{ID: "work", Name: "Work", ChildOrder: 1}, {ID: "home", Name: "Home", ChildOrder: 2}}, nil)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "report", Priority: 4, ProjectID: "work"},
+		{ID: "2", Content: "laundry", Priority: 4, ProjectID: "home"},
+		{ID: "3", Content: "review", Priority: 3, ProjectID: "work"},
+	}
+	changes := &internal.TaskChanges{Since: now.Add(-time.Hour), Previous: map[string]bool{"1": true, "2": true}}
+
+	msg, err := singleMessage(internal.RenderTasksMessage(tasks, now,
+		internal.RenderOptions{GroupBy: internal.GroupByProject, Catalog: catalog, Changes: changes}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "New since 14:00:\n\n📁 Work (1)\n- 🟠 review\n\n" +
+		"Still pending:\n\n📁 Work (1)\n- 🔴 report\n\n📁 Home (1)\n- 🔴 laundry\n"
+	if msg != expected {
+		t.Errorf("expected message %q, got %q", expected, msg)
+	}
+}
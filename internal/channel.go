@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+const (
+	// maxErrorBody limits how much of an error response is kept for the error message.
+	maxErrorBody = 512
+	// channelTimeout bounds the delivery to a channel. It leaves room for a request timeout per
+	// webhook attempt and the backoff between them.
+	channelTimeout = (webhookRetries+1)*defaultTimeout + (1<<webhookRetries-1)*webhookRetryDelay
+	// todoistTodayURL opens the Today view, the click target of push notifications.
+	todoistTodayURL = "https://app.todoist.com/app/today"
+)
+
+// Digest is a scheduled notification as seen by Channels. Tasks are always grouped
+// (by project, or by section with GROUP_BY=section) and link to the Todoist web app.
+type Digest struct {
+	MessageData
+	// RunID identifies the scheduled run, it is the same for every channel.
+	RunID string
+	Lang  Lang
+	// Decisions explain why each open task was included or not.
+	Decisions []FilterDecision
+}
+
+// NewDigest builds the digest of a scheduled notification from the Telegram render options.
+func NewDigest(tasks []todoist.Task, now time.Time, opts RenderOptions, runID string) Digest {
+	opts.Links = LinksWeb
+	opts.Changes = nil
+	if opts.GroupBy != GroupBySection {
+		opts.GroupBy = GroupByProject
+	}
+	return Digest{MessageData: NewMessageData(tasks, now, opts), RunID: runID, Lang: opts.lang()}
+}
+
+// NewChannels returns the channels enabled in the configuration.
+func NewChannels(conf *Config, client HTTPClient, store StateStore) []Channel {
+	var res []Channel
+	if conf.SlackWebhookURL != "" {
+		res = append(res, NewSlackChannel(conf.SlackWebhookURL, client))
+	}
+	if conf.DiscordWebhookURL != "" {
+		res = append(res, NewDiscordChannel(conf.DiscordWebhookURL, client))
+	}
+	if conf.NtfyURL != "" {
+		res = append(res, NewNtfyChannel(conf.NtfyURL, conf.NtfyToken, client))
+	}
+	if conf.GotifyURL != "" {
+		res = append(res, NewGotifyChannel(conf.GotifyURL, conf.GotifyToken, client))
+	}
+	if conf.Matrix.HomeserverURL != "" {
+		res = append(res, NewMatrixChannel(conf.Matrix, conf.Template, store, client))
+	}
+	for _, url := range conf.WebhookURLs {
+		res = append(res, NewWebhookChannel(url, conf.WebhookSecret, client, webhookRetries, webhookRetryDelay))
+	}
+	return res
+}
+
+// notifyChannels sends the digest to every channel concurrently, each with its own timeout, so that
+// neither a slow channel nor the Telegram request delays the others. A failing channel does not stop
+// the others. The returned function waits for all deliveries.
+func (b *Bot) notifyChannels(ctx context.Context, digest Digest) func() {
+	ctx = context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for _, ch := range b.channels {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, channelTimeout)
+			defer cancel()
+			if err := ch.Send(ctx, digest); err != nil {
+				b.log.ErrorContext(ctx, "failed to notify channel", "channel", ch.Name(), "run_id", digest.RunID, "error", err)
+				return
+			}
+			b.log.DebugContext(ctx, "channel notified", "channel", ch.Name(), "run_id", digest.RunID)
+		})
+	}
+	return wg.Wait
+}
+
+// SendDigest sends today's tasks to a channel with its own schedule, e.g. a morning email.
+// Time labels are ignored so that the digest lists the whole day.
+func (b *Bot) SendDigest(ch Channel) error {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	now := b.clock.Now()
+	dayOff := b.isDayOff(now)
+	if dayOff && b.conf.DaysOffMode == DaysOffSkip {
+		b.log.DebugContext(ctx, "day off, skipping digest", "channel", ch.Name())
+		return nil
+	}
+
+	openTasks, err := b.todoistClient.GetTasksLimit200(ctx, false)
+	if err != nil {
+		return fmt.Errorf("get tasks: %w", err)
+	}
+
+	opts, err := b.filterOptions(ctx, false, b.conf.ActiveProfile(now), b.conf.SortStrategy)
+	if err != nil {
+		return err
+	}
+	opts.FilterByTime = false
+	if dayOff {
+		opts.MinPriority = P1
+	}
+	if opts.Catalog == nil {
+		if opts.Catalog, err = b.fetchCatalog(ctx); err != nil {
+			return err
+		}
+	}
+
+	tasks := FilterAndSortTasks(openTasks, now, opts)
+	if len(tasks) == 0 {
+		b.log.DebugContext(ctx, "no tasks, skipping digest", "channel", ch.Name())
+		return nil
+	}
+
+	decisions := ExplainFilter(openTasks, now, opts)
+	renderOpts := RenderOptions{
+		Escalated: escalatedTasks(decisions),
+		Habits:    b.conf.RecurringMode == RecurringHabits,
+		GroupBy:   b.conf.GroupBy,
+		Catalog:   opts.Catalog,
+		Lang:      b.lang(b.conf.TelegramChatID),
+		Fields:    b.conf.TaskFields,
+	}
+	if b.conf.EndOfDay > 0 {
+		workload := EstimateWorkload(dueToday(tasks, now), now, b.conf.EndOfDay)
+		renderOpts.Workload = &workload
+	}
+
+	digest := NewDigest(tasks, now, renderOpts, newRunID())
+	digest.Decisions = decisions
+	if err := ch.Send(ctx, digest); err != nil {
+		return fmt.Errorf("send digest to %s: %w", ch.Name(), err)
+	}
+	b.log.DebugContext(ctx, "digest sent", "channel", ch.Name(), "run_id", digest.RunID)
+	return nil
+}
+
+// taskLine renders a task line for channels. title is the task content, already escaped and linked.
+func taskLine(t TaskData, title string) string {
+	res := toCircle(t.Priority) + " " + title
+	if t.Deadline != "" {
+		res += " ⏰ " + t.Deadline
+	}
+	if t.Reminders > 0 {
+		res += fmt.Sprintf(" 🔁 %d×", t.Reminders)
+	}
+	return res
+}
+
+// pushTitle is the title of push notifications: the header and the number of tasks.
+func pushTitle(d Digest) string {
+	return fmt.Sprintf("%s (%d)", strings.TrimSuffix(d.Lang.T("tasks.header"), ":"), d.Counts.Total)
+}
+
+// pushBody renders the digest as plain text for push notifications, a block per project.
+func pushBody(d Digest) string {
+	var blocks []string
+	block := func(title string, tasks []TaskData) {
+		lines := []string{title}
+		for _, t := range tasks {
+			lines = append(lines, taskLine(t, t.Content))
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+
+	if len(d.Pending) > 0 {
+		block(d.Lang.T("tasks.pending"), d.Pending)
+	}
+	for _, g := range d.Groups {
+		block(g.Title, g.Tasks)
+	}
+	if len(d.Habits) > 0 {
+		names := make([]string, len(d.Habits))
+		for i, h := range d.Habits {
+			names[i] = h.Content
+		}
+		blocks = append(blocks, d.Lang.T("tasks.habits")+" "+strings.Join(names, " · "))
+	}
+	if d.Workload.Overbooked() {
+		w := d.Workload
+		blocks = append(blocks, d.Lang.T("tasks.overbooked",
+			d.Lang.FormatDuration(w.Planned), d.Lang.FormatDuration(w.Available), d.Lang.FormatTime(w.EndOfDay, "15:04")))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func newRunID() string {
+	id := make([]byte, 8) //nolint:mnd // 64 random bits
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// httpResponse is a fully read HTTP response.
+type httpResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// postJSON posts payload encoded as JSON and reads the response.
+func postJSON(ctx context.Context, client HTTPClient, url string, payload any, header http.Header) (httpResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return httpResponse{}, fmt.Errorf("encode payload: %w", err)
+	}
+	return post(ctx, client, url, "application/json", body, header)
+}
+
+func post(ctx context.Context, client HTTPClient, url, contentType string, body []byte, header http.Header) (httpResponse, error) {
+	return doRequest(ctx, client, http.MethodPost, url, contentType, body, header)
+}
+
+func doRequest(ctx context.Context, client HTTPClient, method, url, contentType string, body []byte, header http.Header) (httpResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return httpResponse{}, fmt.Errorf("create request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return httpResponse{}, fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // ignore
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return httpResponse{}, fmt.Errorf("read response: %w", err)
+	}
+
+	return httpResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody}, nil
+}
+
+// statusError is a non 2xx response, with the start of its body.
+type statusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Body)
+}
+
+// checkStatus returns a statusError for a non 2xx response.
+func (r httpResponse) checkStatus() error {
+	if r.StatusCode >= 200 && r.StatusCode < 300 {
+		return nil
+	}
+	body := r.Body
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody]
+	}
+	return statusError{StatusCode: r.StatusCode, Body: body}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("wait: %w", ctx.Err())
+	}
+}
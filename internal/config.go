@@ -6,26 +6,126 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
-	Dev              bool
-	TodoistToken     string
-	TelegramToken    string
-	TelegramChatID   int64
-	Schedule         string
+	Dev            bool
+	TodoistToken   string
+	TelegramToken  string
+	TelegramChatID int64
+	Schedule       string
+	// TomorrowPreviewSchedule is the cron schedule of the evening preview of tomorrow's tasks. Empty disables it.
+	TomorrowPreviewSchedule string
+	// SummarySchedule is the cron schedule of the evening progress summary. Empty disables it.
+	SummarySchedule  string
 	Location         string
 	IgnoreProjectIDs []string
+	LabelRule        Rule
+	SectionRule      Rule
+	ProjectRule      Rule
+	SortStrategy     SortStrategy
+	DeadlineWindow   time.Duration
+	QuietHours       *QuietHours
+	RecurringMode    RecurringMode
+	GroupBy          GroupMode
+	MessageFormat    Format
+	TaskLinks        TaskLinks
+	// TaskFields are the optional details shown on task lines.
+	TaskFields TaskFields
+	// Language is the default language of bot messages, chats can override it with /language.
+	Language Lang
+	// Template replaces the built-in task message template when TEMPLATE_FILE is set.
+	Template *MessageTemplate
+	// RecurringFrom (time since midnight) hides recurring tasks in scheduled notifications until then.
+	RecurringFrom time.Duration
+	// EndOfDay (time since midnight) is when today's estimated work should be done. Zero disables the warning.
+	EndOfDay time.Duration
+	// Profiles override the default schedule and filtering on matching days.
+	Profiles []Profile
+	// DaysOff are holidays from DAYS_OFF and DAYS_OFF_ICS, handled according to DaysOffMode.
+	DaysOff     []DateRange
+	DaysOffMode DaysOffMode
+	StateFile   string
+	// NagThreshold is the number of scheduled notifications after which a task is shown as still pending.
+	// Zero disables nagging.
+	NagThreshold int
+	// NagAlert sends an extra alert message when there are still pending tasks.
+	NagAlert bool
+	// NotifyMode selects between the full task list and the changes since the previous notification.
+	NotifyMode NotifyMode
+	// SkipUnchanged skips scheduled notifications whose tasks are the same as in the previous one.
+	SkipUnchanged bool
+	// LiveMessage edits a single pinned message per day instead of sending scheduled notifications.
+	LiveMessage bool
+	// SlackWebhookURL enables the Slack channel.
+	SlackWebhookURL string
+	// DiscordWebhookURL enables the Discord channel.
+	DiscordWebhookURL string
+	// NtfyURL is the ntfy topic URL, e.g. https://ntfy.sh/my-tasks. It enables the ntfy channel.
+	NtfyURL string
+	// NtfyToken is the optional ntfy access token.
+	NtfyToken string
+	// GotifyURL is the Gotify server URL. It enables the Gotify channel.
+	GotifyURL string
+	// GotifyToken is the Gotify application token.
+	GotifyToken string
+	// WebhookURLs receive the signed JSON digest.
+	WebhookURLs []string
+	// WebhookSecret is the HMAC-SHA256 key of webhook signatures.
+	WebhookSecret string
+	// Matrix enables the Matrix channel when Matrix.HomeserverURL is set.
+	Matrix MatrixConfig
+	// Email enables the email digest when Email.Host is set.
+	Email EmailConfig
+	// EmailSchedule is the cron schedule of the email digest.
+	EmailSchedule string
 }
 
 func GetConfig(ctx context.Context) (*Config, error) {
 	res := &Config{
-		Dev:              os.Getenv("ENV") == "dev",
-		TodoistToken:     os.Getenv("TODOIST_TOKEN"),
-		TelegramToken:    os.Getenv("TELEGRAM_BOT_ID"),
-		Schedule:         os.Getenv("SCHEDULE"),
-		Location:         os.Getenv("LOCATION"),
-		IgnoreProjectIDs: strings.Split(os.Getenv("IGNORE_PROJECT_IDS"), ","),
+		Dev:                     os.Getenv("ENV") == "dev",
+		TodoistToken:            os.Getenv("TODOIST_TOKEN"),
+		TelegramToken:           os.Getenv("TELEGRAM_BOT_ID"),
+		Schedule:                os.Getenv("SCHEDULE"),
+		TomorrowPreviewSchedule: os.Getenv("TOMORROW_PREVIEW_SCHEDULE"),
+		SummarySchedule:         os.Getenv("SUMMARY_SCHEDULE"),
+		Location:                os.Getenv("LOCATION"),
+		IgnoreProjectIDs:        strings.Split(os.Getenv("IGNORE_PROJECT_IDS"), ","),
+		LabelRule:               ruleFromEnv("INCLUDE_LABELS", "EXCLUDE_LABELS"),
+		SectionRule:             ruleFromEnv("INCLUDE_SECTIONS", "EXCLUDE_SECTIONS"),
+		ProjectRule:             ruleFromEnv("INCLUDE_PROJECTS", "EXCLUDE_PROJECTS"),
+		DaysOffMode:             DaysOffMode(os.Getenv("DAYS_OFF_MODE")),
+		StateFile:               os.Getenv("STATE_FILE"),
+		NagAlert:                os.Getenv("NAG_ALERT") == "true",
+		NotifyMode:              NotifyMode(os.Getenv("NOTIFY_MODE")),
+		SkipUnchanged:           os.Getenv("SKIP_UNCHANGED") == "true",
+		LiveMessage:             os.Getenv("LIVE_MESSAGE") == "true",
+		SlackWebhookURL:         os.Getenv("SLACK_WEBHOOK_URL"),
+		DiscordWebhookURL:       os.Getenv("DISCORD_WEBHOOK_URL"),
+		NtfyURL:                 os.Getenv("NTFY_URL"),
+		NtfyToken:               os.Getenv("NTFY_TOKEN"),
+		GotifyURL:               os.Getenv("GOTIFY_URL"),
+		GotifyToken:             os.Getenv("GOTIFY_TOKEN"),
+		WebhookURLs:             listFromEnv("WEBHOOK_URLS"),
+		WebhookSecret:           os.Getenv("WEBHOOK_SECRET"),
+		EmailSchedule:           os.Getenv("EMAIL_SCHEDULE"),
+		RecurringMode:           RecurringMode(os.Getenv("RECURRING")),
+		GroupBy:                 GroupMode(os.Getenv("GROUP_BY")),
+		Email: EmailConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			StartTLS: os.Getenv("SMTP_STARTTLS") != "false",
+			From:     os.Getenv("EMAIL_FROM"),
+			To:       listFromEnv("EMAIL_TO"),
+		},
+		Matrix: MatrixConfig{
+			HomeserverURL: os.Getenv("MATRIX_HOMESERVER_URL"),
+			AccessToken:   os.Getenv("MATRIX_ACCESS_TOKEN"),
+			RoomID:        os.Getenv("MATRIX_ROOM_ID"),
+			Edit:          os.Getenv("MATRIX_EDIT") == "true",
+		},
 	}
 	telegramChatID := os.Getenv("TELEGRAM_CHAT_ID")
 	if res.Schedule == "" {
@@ -34,6 +134,24 @@ func GetConfig(ctx context.Context) (*Config, error) {
 	if res.Location == "" {
 		res.Location = "Europe/Kyiv"
 	}
+	if res.GroupBy == "" {
+		res.GroupBy = GroupNone
+	}
+	if res.RecurringMode == "" {
+		res.RecurringMode = RecurringShow
+	}
+	if res.NotifyMode == "" {
+		res.NotifyMode = NotifyFull
+	}
+	if res.DaysOffMode == "" {
+		res.DaysOffMode = DaysOffSkip
+	}
+	if res.EmailSchedule == "" {
+		res.EmailSchedule = "0 8 * * *"
+	}
+	if res.StateFile == "" {
+		res.StateFile = "data/state.json"
+	}
 
 	// In dev mode or if all required params are set via env vars, skip SSM
 	if res.Dev || hasRequiredParams(res, telegramChatID) {
@@ -52,7 +170,24 @@ func GetConfig(ctx context.Context) (*Config, error) {
 
 // hasRequiredParams checks if all required parameters are already set via environment variables
 func hasRequiredParams(conf *Config, telegramChatID string) bool {
-	return conf.TodoistToken != "" && conf.TelegramToken != "" && telegramChatID != ""
+	return conf.TodoistToken != "" && ((conf.TelegramToken != "" && telegramChatID != "") || conf.HasChannels() || conf.EmailEnabled())
+}
+
+// TelegramEnabled reports whether the Telegram bot is configured. Without it only the other channels are notified.
+func (c *Config) TelegramEnabled() bool {
+	return c.TelegramToken != ""
+}
+
+// HasChannels reports whether any channel besides Telegram gets scheduled notifications. The email
+// digest has its own schedule and is not one of them.
+func (c *Config) HasChannels() bool {
+	return c.SlackWebhookURL != "" || c.DiscordWebhookURL != "" || c.NtfyURL != "" || c.GotifyURL != "" ||
+		len(c.WebhookURLs) != 0 || c.Matrix.HomeserverURL != ""
+}
+
+// EmailEnabled reports whether the email digest is configured.
+func (c *Config) EmailEnabled() bool {
+	return c.Email.Host != ""
 }
 
 func (c *Config) validate(telegramChatID string) error {
@@ -61,22 +196,216 @@ func (c *Config) validate(telegramChatID string) error {
 	if c.TodoistToken == "" {
 		missing = append(missing, "TODOIST_TOKEN")
 	}
-	if c.TelegramToken == "" {
-		missing = append(missing, "TELEGRAM_BOT_ID")
+	// Telegram is optional when another channel or the email digest is configured
+	if c.TelegramToken != "" || telegramChatID != "" || !(c.HasChannels() || c.EmailEnabled()) {
+		if c.TelegramToken == "" {
+			missing = append(missing, "TELEGRAM_BOT_ID")
+		}
+		if telegramChatID == "" {
+			missing = append(missing, "TELEGRAM_CHAT_ID")
+		}
+	}
+	if c.GotifyURL != "" && c.GotifyToken == "" {
+		missing = append(missing, "GOTIFY_TOKEN")
 	}
-	if telegramChatID == "" {
-		missing = append(missing, "TELEGRAM_CHAT_ID")
+	if len(c.WebhookURLs) != 0 && c.WebhookSecret == "" {
+		missing = append(missing, "WEBHOOK_SECRET")
+	}
+	if c.Matrix.HomeserverURL != "" {
+		if c.Matrix.AccessToken == "" {
+			missing = append(missing, "MATRIX_ACCESS_TOKEN")
+		}
+		if c.Matrix.RoomID == "" {
+			missing = append(missing, "MATRIX_ROOM_ID")
+		}
+	}
+	if c.EmailEnabled() {
+		if c.Email.From == "" {
+			missing = append(missing, "EMAIL_FROM")
+		}
+		if len(c.Email.To) == 0 {
+			missing = append(missing, "EMAIL_TO")
+		}
 	}
 
 	if len(missing) > 0 {
 		return fmt.Errorf("required environment variables not set: %v", missing)
 	}
 
+	for name, rule := range map[string]Rule{"labels": c.LabelRule, "sections": c.SectionRule, "projects": c.ProjectRule} {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%s filter: %w", name, err)
+		}
+	}
+
 	var err error
-	c.TelegramChatID, err = strconv.ParseInt(telegramChatID, 10, 64)
+	c.SortStrategy, err = ParseSortStrategy(os.Getenv("SORT"))
 	if err != nil {
-		return fmt.Errorf("parse TELEGRAM_CHAT_ID: %w", err)
+		return fmt.Errorf("parse SORT: %w", err)
+	}
+
+	if telegramChatID != "" {
+		if c.TelegramChatID, err = strconv.ParseInt(telegramChatID, 10, 64); err != nil {
+			return fmt.Errorf("parse TELEGRAM_CHAT_ID: %w", err)
+		}
+	}
+
+	if c.NtfyURL != "" {
+		if _, _, err := splitNtfyURL(c.NtfyURL); err != nil {
+			return fmt.Errorf("parse NTFY_URL: %w", err)
+		}
+	}
+
+	c.Email.Port = 587 //nolint:mnd // SMTP submission
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		if c.Email.Port, err = strconv.Atoi(port); err != nil {
+			return fmt.Errorf("parse SMTP_PORT: %w", err)
+		}
+	}
+
+	c.DeadlineWindow = 48 * time.Hour //nolint:mnd // two days
+	if window := os.Getenv("DEADLINE_WINDOW"); window != "" {
+		if c.DeadlineWindow, err = time.ParseDuration(window); err != nil {
+			return fmt.Errorf("parse DEADLINE_WINDOW: %w", err)
+		}
+	}
+
+	c.NagThreshold = 6 //nolint:mnd // six hourly reminders
+	if threshold := os.Getenv("NAG_THRESHOLD"); threshold != "" {
+		if c.NagThreshold, err = strconv.Atoi(threshold); err != nil {
+			return fmt.Errorf("parse NAG_THRESHOLD: %w", err)
+		}
+		if c.NagThreshold < 0 {
+			return fmt.Errorf("invalid NAG_THRESHOLD %d, expected 0 or more", c.NagThreshold)
+		}
+	}
+
+	if c.RecurringMode != RecurringShow && c.RecurringMode != RecurringHabits && c.RecurringMode != RecurringExclude {
+		return fmt.Errorf("invalid RECURRING %q, expected %s, %s or %s", c.RecurringMode, RecurringShow, RecurringHabits, RecurringExclude)
+	}
+	if c.NotifyMode != NotifyFull && c.NotifyMode != NotifyDiff {
+		return fmt.Errorf("invalid NOTIFY_MODE %q, expected %s or %s", c.NotifyMode, NotifyFull, NotifyDiff)
+	}
+	if c.GroupBy != GroupNone && c.GroupBy != GroupByProject && c.GroupBy != GroupBySection {
+		return fmt.Errorf("invalid GROUP_BY %q, expected %s, %s or %s", c.GroupBy, GroupNone, GroupByProject, GroupBySection)
+	}
+	if c.MessageFormat, err = ParseFormat(os.Getenv("MESSAGE_FORMAT")); err != nil {
+		return fmt.Errorf("parse MESSAGE_FORMAT: %w", err)
+	}
+	if c.TaskLinks, err = ParseTaskLinks(os.Getenv("TASK_LINKS")); err != nil {
+		return fmt.Errorf("parse TASK_LINKS: %w", err)
+	}
+	if c.TaskFields, err = ParseTaskFields(os.Getenv("TASK_FIELDS")); err != nil {
+		return fmt.Errorf("parse TASK_FIELDS: %w", err)
+	}
+	if maxContent := os.Getenv("TASK_CONTENT_MAX"); maxContent != "" {
+		if c.TaskFields.MaxContent, err = strconv.Atoi(maxContent); err != nil {
+			return fmt.Errorf("parse TASK_CONTENT_MAX: %w", err)
+		}
+	}
+	c.TaskFields.MaxDescription = 80 //nolint:mnd // about one line on a phone
+	if maxDescription := os.Getenv("TASK_DESCRIPTION_MAX"); maxDescription != "" {
+		if c.TaskFields.MaxDescription, err = strconv.Atoi(maxDescription); err != nil {
+			return fmt.Errorf("parse TASK_DESCRIPTION_MAX: %w", err)
+		}
+	}
+	if c.Language, err = ParseLang(os.Getenv("LANGUAGE")); err != nil {
+		return fmt.Errorf("parse LANGUAGE: %w", err)
+	}
+	if templateFile := os.Getenv("TEMPLATE_FILE"); templateFile != "" {
+		if c.Template, err = LoadMessageTemplate(templateFile); err != nil {
+			return fmt.Errorf("load TEMPLATE_FILE: %w", err)
+		}
+	}
+	if recurringFrom := os.Getenv("RECURRING_FROM"); recurringFrom != "" {
+		if c.RecurringFrom, err = parseClock(recurringFrom); err != nil {
+			return fmt.Errorf("parse RECURRING_FROM: %w", err)
+		}
+	}
+
+	c.EndOfDay = 22 * time.Hour //nolint:mnd // 10pm
+	if endOfDay := os.Getenv("END_OF_DAY"); endOfDay == "off" {
+		c.EndOfDay = 0
+	} else if endOfDay != "" {
+		if c.EndOfDay, err = parseClock(endOfDay); err != nil {
+			return fmt.Errorf("parse END_OF_DAY: %w", err)
+		}
+		if c.EndOfDay == 0 {
+			// 00:00 is the end of the day, zero would disable the warning
+			c.EndOfDay = 24 * time.Hour //nolint:mnd // midnight
+		}
+	}
+
+	if quietHours := os.Getenv("QUIET_HOURS"); quietHours != "" {
+		if c.QuietHours, err = ParseQuietHours(quietHours); err != nil {
+			return fmt.Errorf("parse QUIET_HOURS: %w", err)
+		}
+	}
+
+	if c.DaysOff, err = ParseDaysOff(os.Getenv("DAYS_OFF")); err != nil {
+		return fmt.Errorf("parse DAYS_OFF: %w", err)
+	}
+	if icsFile := os.Getenv("DAYS_OFF_ICS"); icsFile != "" {
+		fromICS, err := LoadICS(icsFile)
+		if err != nil {
+			return fmt.Errorf("load DAYS_OFF_ICS: %w", err)
+		}
+		c.DaysOff = append(c.DaysOff, fromICS...)
+	}
+	if c.DaysOffMode != DaysOffSkip && c.DaysOffMode != DaysOffDowngrade {
+		return fmt.Errorf("invalid DAYS_OFF_MODE %q, expected %s or %s", c.DaysOffMode, DaysOffSkip, DaysOffDowngrade)
+	}
+
+	if profilesFile := os.Getenv("PROFILES_FILE"); profilesFile != "" {
+		if c.Profiles, err = LoadProfiles(profilesFile, c.DefaultProfile()); err != nil {
+			return fmt.Errorf("load PROFILES_FILE: %w", err)
+		}
 	}
 
 	return nil
 }
+
+// DefaultProfile returns the profile built from the top-level settings. It applies on days
+// no configured profile matches.
+func (c *Config) DefaultProfile() Profile {
+	return Profile{
+		Name:             defaultProfileName,
+		Schedule:         c.Schedule,
+		FilterByTime:     true,
+		IgnoreProjectIDs: c.IgnoreProjectIDs,
+		QuietHours:       c.QuietHours,
+	}
+}
+
+// AllProfiles returns the configured profiles followed by the default one.
+func (c *Config) AllProfiles() []Profile {
+	return append(append([]Profile{}, c.Profiles...), c.DefaultProfile())
+}
+
+// ActiveProfile returns the first configured profile matching t, or the default profile.
+func (c *Config) ActiveProfile(t time.Time) Profile {
+	for _, p := range c.Profiles {
+		if p.Matches(t) {
+			return p
+		}
+	}
+	return c.DefaultProfile()
+}
+
+func ruleFromEnv(includeKey, excludeKey string) Rule {
+	return Rule{
+		Include: listFromEnv(includeKey),
+		Exclude: listFromEnv(excludeKey),
+	}
+}
+
+// listFromEnv splits a comma separated environment variable, dropping empty items.
+func listFromEnv(key string) []string {
+	var res []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			res = append(res, v)
+		}
+	}
+	return res
+}
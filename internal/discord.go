@@ -0,0 +1,263 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	discordMaxFields     = 25
+	discordMaxFieldName  = 256
+	discordMaxFieldValue = 1024
+	discordMaxTitle      = 256
+	discordMaxFooter     = 2048
+	// discordMaxEmbedText is the limit of all the text of the embeds of a message.
+	discordMaxEmbedText = 6000
+	// discordMaxEmbeds is how many embeds a message can have.
+	discordMaxEmbeds = 10
+	// discordMaxRetries is how many times a rate limited message is retried.
+	discordMaxRetries = 3
+	// discordMaxWait is how long a rate limit may delay a message, longer limits fail the delivery.
+	discordMaxWait = 5 * time.Second
+)
+
+// DiscordChannel posts digests to a Discord webhook as embeds, as few messages as the limits allow.
+type DiscordChannel struct {
+	webhookURL string
+	client     HTTPClient
+}
+
+type discordMessage struct {
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title  string         `json:"title,omitempty"`
+	Color  int            `json:"color"`
+	Fields []discordField `json:"fields"`
+	Footer *discordFooter `json:"footer,omitempty"`
+}
+
+type discordField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+func NewDiscordChannel(webhookURL string, client HTTPClient) *DiscordChannel {
+	return &DiscordChannel{webhookURL: webhookURL, client: client}
+}
+
+func (c *DiscordChannel) Name() string {
+	return "discord"
+}
+
+func (c *DiscordChannel) Send(ctx context.Context, digest Digest) error {
+	msgs := newDiscordMessages(newDiscordEmbeds(digest))
+	for i, msg := range msgs {
+		resp, err := c.post(ctx, msg)
+		if err != nil {
+			return fmt.Errorf("post discord message %d/%d: %w", i+1, len(msgs), err)
+		}
+		// the bucket is empty, wait for it to refill before the next message
+		if i < len(msgs)-1 && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if err := waitRateLimit(ctx, discordResetAfter(resp)); err != nil {
+				return fmt.Errorf("wait before discord message %d/%d: %w", i+2, len(msgs), err)
+			}
+		}
+	}
+	return nil
+}
+
+// newDiscordMessages packs embeds into messages, within the embed count and text limits of a message.
+func newDiscordMessages(embeds []discordEmbed) []discordMessage {
+	var (
+		res  []discordMessage
+		size int
+	)
+	for _, e := range embeds {
+		embedSize := discordEmbedSize(e)
+		if len(res) == 0 || len(res[len(res)-1].Embeds) == discordMaxEmbeds || size+embedSize > discordMaxEmbedText {
+			res = append(res, discordMessage{})
+			size = 0
+		}
+		last := &res[len(res)-1]
+		last.Embeds = append(last.Embeds, e)
+		size += embedSize
+	}
+	return res
+}
+
+// post sends a message, waiting and retrying when Discord rate limits it.
+func (c *DiscordChannel) post(ctx context.Context, msg discordMessage) (httpResponse, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := postJSON(ctx, c.client, c.webhookURL, msg, nil)
+		if err != nil {
+			return httpResponse{}, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt == discordMaxRetries {
+			return resp, resp.checkStatus()
+		}
+		if err := waitRateLimit(ctx, discordRetryAfter(resp)); err != nil {
+			return httpResponse{}, err
+		}
+	}
+}
+
+// newDiscordEmbeds renders the digest as embeds colored by the highest priority, with a field per
+// project. Fields are split and spread over several embeds to stay within Discord's limits.
+func newDiscordEmbeds(d Digest) []discordEmbed {
+	var fields []discordField
+	if len(d.Pending) > 0 {
+		fields = append(fields, discordFields(d.Lang.T("tasks.pending"), d.Pending)...)
+	}
+	for _, g := range d.Groups {
+		fields = append(fields, discordFields(g.Title, g.Tasks)...)
+	}
+	if len(d.Habits) > 0 {
+		fields = append(fields, discordFields(d.Lang.T("tasks.habits"), d.Habits)...)
+	}
+
+	color := discordColor(d.Counts)
+	first := discordEmbed{Title: clip(d.Lang.T("tasks.header"), discordMaxTitle), Color: color}
+	if d.Workload.Overbooked() {
+		w := d.Workload
+		first.Footer = &discordFooter{Text: clip(d.Lang.T("tasks.overbooked",
+			d.Lang.FormatDuration(w.Planned), d.Lang.FormatDuration(w.Available), d.Lang.FormatTime(w.EndOfDay, "15:04")), discordMaxFooter)}
+	}
+
+	res := []discordEmbed{first}
+	size := discordEmbedSize(first)
+	for _, f := range fields {
+		last := &res[len(res)-1]
+		fieldSize := utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+		if len(last.Fields) == discordMaxFields || size+fieldSize > discordMaxEmbedText {
+			res = append(res, discordEmbed{Color: color})
+			last, size = &res[len(res)-1], 0
+		}
+		last.Fields = append(last.Fields, f)
+		size += fieldSize
+	}
+	return res
+}
+
+// discordFields renders a titled list of tasks as fields within the field value limit.
+// Continuation fields repeat the name with their number, e.g. "Work (2)".
+func discordFields(name string, tasks []TaskData) []discordField {
+	var (
+		res []discordField
+		cur string
+	)
+	flush := func() {
+		fieldName := name
+		if len(res) > 0 {
+			fieldName = fmt.Sprintf("%s (%d)", name, len(res)+1)
+		}
+		res = append(res, discordField{Name: clip(fieldName, discordMaxFieldName), Value: cur})
+		cur = ""
+	}
+	for _, t := range tasks {
+		line := clip(taskLine(t, discordLink(t)), discordMaxFieldValue)
+		if cur != "" && utf8.RuneCountInString(cur)+1+utf8.RuneCountInString(line) > discordMaxFieldValue {
+			flush()
+		}
+		if cur != "" {
+			cur += "\n"
+		}
+		cur += line
+	}
+	flush()
+	return res
+}
+
+func discordEmbedSize(e discordEmbed) int {
+	res := utf8.RuneCountInString(e.Title)
+	if e.Footer != nil {
+		res += utf8.RuneCountInString(e.Footer.Text)
+	}
+	for _, f := range e.Fields {
+		res += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+	}
+	return res
+}
+
+// discordColor returns the color of the highest priority among the tasks.
+func discordColor(c Counts) int {
+	rgb := priorityColors[c.topPriority()]
+	return int(rgb.R)<<16 | int(rgb.G)<<8 | int(rgb.B)
+}
+
+// discordLink renders the task content as a masked link, bold for P1.
+func discordLink(t TaskData) string {
+	res := discordEscape(t.Content)
+	if Priority(t.Priority) == P1 {
+		res = "**" + res + "**"
+	}
+	if t.URL == "" {
+		return res
+	}
+	return "[" + res + "](" + t.URL + ")"
+}
+
+// discordEscape escapes inline Discord markdown. Line level markup (quotes, headings, lists) needs
+// no escaping as task lines start with the priority circle.
+func discordEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\*_~`|[]()", r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// discordRetryAfter returns how long to wait after a 429 response, from the Retry-After header
+// or the retry_after field of the body.
+func discordRetryAfter(resp httpResponse) time.Duration {
+	if d, ok := parseSeconds(resp.Header.Get("Retry-After")); ok {
+		return d
+	}
+	var body struct {
+		RetryAfter float64 `json:"retry_after"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err == nil && body.RetryAfter > 0 {
+		return time.Duration(body.RetryAfter * float64(time.Second))
+	}
+	return time.Second
+}
+
+// discordResetAfter returns how long until the rate limit bucket refills.
+func discordResetAfter(resp httpResponse) time.Duration {
+	if d, ok := parseSeconds(resp.Header.Get("X-RateLimit-Reset-After")); ok {
+		return d
+	}
+	return time.Second
+}
+
+// parseSeconds parses a (fractional) number of seconds.
+func parseSeconds(s string) (time.Duration, bool) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 {
+		return 0, false
+	}
+	return time.Duration(v * float64(time.Second)), true
+}
+
+// waitRateLimit waits out a rate limit. A retry before it ends would be rejected again, so a limit
+// longer than discordMaxWait fails right away.
+func waitRateLimit(ctx context.Context, d time.Duration) error {
+	if d > discordMaxWait {
+		return fmt.Errorf("rate limited for %s, longer than %s", d, discordMaxWait)
+	}
+	return sleep(ctx, d)
+}
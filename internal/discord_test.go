@@ -0,0 +1,197 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+type discordMessage struct {
+	Embeds []struct {
+		Title  string `json:"title"`
+		Color  int    `json:"color"`
+		Fields []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"fields"`
+	} `json:"embeds"`
+}
+
+func decodeDiscordMessages(t *testing.T, bodies [][]byte) []discordMessage {
+	t.Helper()
+	res := make([]discordMessage, len(bodies))
+	for i, body := range bodies {
+		if err := json.Unmarshal(body, &res[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res
+}
+
+func TestDiscordChannel(t *testing.T) {
+	srv, _, bodies := recordingServer(t, http.StatusNoContent)
+
+	if err := internal.NewDiscordChannel(srv.URL, srv.Client()).Send(context.Background(), testDigest()); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := decodeDiscordMessages(t, *bodies)
+	if len(msgs) != 1 || len(msgs[0].Embeds) != 1 {
+		t.Fatalf("expected a single embed, got %+v", msgs)
+	}
+	embed := msgs[0].Embeds[0]
+	if embed.Title != "Uncompleted tasks for today:" {
+		t.Errorf("unexpected title %q", embed.Title)
+	}
+	if embed.Color != 0xD1453B {
+		t.Errorf("expected P1 color, got %06X", embed.Color)
+	}
+	if len(embed.Fields) != 3 {
+		t.Fatalf("expected Work, Home and Habits fields, got %+v", embed.Fields)
+	}
+	work := "🔴 [**Fix <prod> & deploy**](https://app.todoist.com/app/task/1)\n" +
+		"🔵 [review](https://app.todoist.com/app/task/3)"
+	if embed.Fields[0].Name != "Work" || embed.Fields[0].Value != work {
+		t.Errorf("expected work field %q, got %+v", work, embed.Fields[0])
+	}
+	if embed.Fields[1].Name != "Home" || embed.Fields[2].Name != "Habits:" {
+		t.Errorf("unexpected fields %+v", embed.Fields[1:])
+	}
+}
+
+func TestDiscordChannel_Limits(t *testing.T) {
+	srv, _, bodies := recordingServer(t, http.StatusNoContent)
+
+	var (
+		projects []todoist.Project
+		tasks    []todoist.Task
+	)
+	for p := range 30 {
+		id := fmt.Sprintf("p%d", p)
+		projects = append(projects, todoist.Project{ID: id, Name: "Project " + id, ChildOrder: p})
+		for i := range 10 {
+			content := fmt.Sprintf("task %d-%d %s", p, i, strings.Repeat("x", 100))
+			tasks = append(tasks, todoist.Task{ID: fmt.Sprintf("%d-%d", p, i), Content: content, Priority: 1, ProjectID: id})
+		}
+	}
+	digest := internal.NewDigest(tasks, testDigest().Now, internal.RenderOptions{Catalog: internal.NewCatalog(projects, nil)}, "run-1")
+
+	if err := internal.NewDiscordChannel(srv.URL, srv.Client()).Send(context.Background(), digest); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := decodeDiscordMessages(t, *bodies)
+	if len(msgs) < 2 {
+		t.Fatalf("expected the digest to be split, got %d messages", len(msgs))
+	}
+	lines := 0
+	for _, msg := range msgs {
+		if len(msg.Embeds) > 10 {
+			t.Errorf("expected at most 10 embeds, got %d", len(msg.Embeds))
+		}
+		size := 0
+		for _, e := range msg.Embeds {
+			size += utf8.RuneCountInString(e.Title)
+			if len(e.Fields) > 25 {
+				t.Errorf("expected at most 25 fields, got %d", len(e.Fields))
+			}
+			for _, f := range e.Fields {
+				if n := utf8.RuneCountInString(f.Value); n > 1024 {
+					t.Errorf("expected field value within 1024 characters, got %d", n)
+				}
+				size += utf8.RuneCountInString(f.Name) + utf8.RuneCountInString(f.Value)
+				lines += strings.Count(f.Value, "\n") + 1
+			}
+		}
+		if size > 6000 {
+			t.Errorf("expected message within 6000 characters, got %d", size)
+		}
+	}
+	if lines != len(tasks) {
+		t.Errorf("expected %d task lines, got %d", len(tasks), lines)
+	}
+}
+
+func TestDiscordChannel_Batch(t *testing.T) {
+	srv, _, bodies := recordingServer(t, http.StatusNoContent)
+
+	var (
+		projects []todoist.Project
+		tasks    []todoist.Task
+	)
+	for p := range 60 {
+		id := fmt.Sprintf("p%d", p)
+		projects = append(projects, todoist.Project{ID: id, Name: "Project " + id, ChildOrder: p})
+		tasks = append(tasks, todoist.Task{ID: id, Content: "task " + id, Priority: 1, ProjectID: id})
+	}
+	digest := internal.NewDigest(tasks, testDigest().Now, internal.RenderOptions{Catalog: internal.NewCatalog(projects, nil)}, "run-1")
+
+	if err := internal.NewDiscordChannel(srv.URL, srv.Client()).Send(context.Background(), digest); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := decodeDiscordMessages(t, *bodies)
+	if len(msgs) != 1 || len(msgs[0].Embeds) != 3 {
+		t.Fatalf("expected a single message with 3 embeds of up to 25 fields, got %+v", msgs)
+	}
+	if msgs[0].Embeds[0].Title != "Uncompleted tasks for today:" || msgs[0].Embeds[1].Title != "" {
+		t.Errorf("expected only the first embed to have a title, got %+v", msgs[0].Embeds)
+	}
+}
+
+func TestDiscordChannel_RateLimit(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0.01")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": 0.01}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	if err := internal.NewDiscordChannel(srv.URL, srv.Client()).Send(context.Background(), testDigest()); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the rate limited message to be retried, got %d calls", calls)
+	}
+}
+
+func TestDiscordChannel_RateLimitTooLong(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(srv.Close)
+
+	start := time.Now()
+	if err := internal.NewDiscordChannel(srv.URL, srv.Client()).Send(context.Background(), testDigest()); err == nil {
+		t.Fatal("expected error for a rate limit longer than the wait budget")
+	}
+	if calls != 1 || time.Since(start) > time.Second {
+		t.Errorf("expected to fail without waiting or retrying, got %d calls in %s", calls, time.Since(start))
+	}
+}
+
+func TestDiscordChannel_Error(t *testing.T) {
+	srv, _, _ := recordingServer(t, http.StatusNotFound)
+
+	if err := internal.NewDiscordChannel(srv.URL, srv.Client()).Send(context.Background(), testDigest()); err == nil {
+		t.Error("expected error for unknown webhook")
+	}
+}
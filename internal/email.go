@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EmailConfig configures the SMTP server and the addresses of the email digest.
+type EmailConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// StartTLS requires the connection to be upgraded with STARTTLS before authenticating.
+	StartTLS bool
+	// TLSConfig overrides the STARTTLS settings, e.g. trusted CAs. Nil verifies Host against the system roots.
+	TLSConfig *tls.Config
+	From      string
+	To        []string
+}
+
+// EmailChannel mails digests as multipart plain text and HTML, rendered with the task message template.
+type EmailChannel struct {
+	conf     EmailConfig
+	template *MessageTemplate
+}
+
+// NewEmailChannel returns an email channel. A nil template uses the built-in one.
+func NewEmailChannel(conf EmailConfig, template *MessageTemplate) *EmailChannel {
+	if template == nil {
+		template = tasksTemplate
+	}
+	return &EmailChannel{conf: conf, template: template}
+}
+
+func (c *EmailChannel) Name() string {
+	return "email"
+}
+
+func (c *EmailChannel) Send(ctx context.Context, digest Digest) error {
+	msg, err := c.message(digest)
+	if err != nil {
+		return fmt.Errorf("render email: %w", err)
+	}
+	if err := c.send(ctx, msg); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+// message renders the digest as a MIME message with plain text and HTML alternatives.
+func (c *EmailChannel) message(d Digest) ([]byte, error) {
+	plain, err := c.template.execute(FormatPlain, d.Lang, d.MessageData)
+	if err != nil {
+		return nil, fmt.Errorf("render plain text: %w", err)
+	}
+	html, err := c.template.execute(FormatHTML, d.Lang, d.MessageData)
+	if err != nil {
+		return nil, fmt.Errorf("render html: %w", err)
+	}
+	// Telegram HTML has no paragraphs, keep its line breaks
+	html = `<!DOCTYPE html><html><body><div style="white-space: pre-wrap; font-family: sans-serif">` +
+		html + "</div></body></html>"
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, text string }{
+		{"text/plain; charset=utf-8", plain},
+		{"text/html; charset=utf-8", html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.text)); err != nil {
+			return nil, fmt.Errorf("write part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("write part: %w", err)
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("close multipart: %w", err)
+	}
+
+	var res bytes.Buffer
+	for _, h := range [][2]string{
+		{"From", c.conf.From},
+		{"To", strings.Join(c.conf.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", d.Lang.T("email.subject", d.Lang.FormatDate(d.Now)))},
+		{"Date", d.Now.Format(time.RFC1123Z)},
+		{"Message-ID", "<" + d.RunID + "@todoist-notifier>"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()})},
+	} {
+		res.WriteString(h[0] + ": " + h[1] + "\r\n")
+	}
+	res.WriteString("\r\n")
+	res.Write(body.Bytes())
+	return res.Bytes(), nil
+}
+
+func (c *EmailChannel) send(ctx context.Context, msg []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(c.conf.Host, strconv.Itoa(c.conf.Port)))
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, c.conf.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("greeting: %w", err)
+	}
+	defer client.Close() //nolint:errcheck // closed after QUIT already
+
+	if c.conf.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not support STARTTLS")
+		}
+		tlsConf := &tls.Config{MinVersion: tls.VersionTLS12}
+		if c.conf.TLSConfig != nil {
+			tlsConf = c.conf.TLSConfig.Clone()
+		}
+		if tlsConf.ServerName == "" {
+			tlsConf.ServerName = c.conf.Host
+		}
+		if err := client.StartTLS(tlsConf); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if c.conf.Username != "" {
+		// PlainAuth refuses to send the password over an unencrypted connection to a remote host
+		if err := client.Auth(smtp.PlainAuth("", c.conf.Username, c.conf.Password, c.conf.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.conf.From); err != nil {
+		return fmt.Errorf("mail from: %w", err)
+	}
+	for _, to := range c.conf.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("rcpt to %s: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("write message: %w", err)
+	}
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("quit: %w", err)
+	}
+	return nil
+}
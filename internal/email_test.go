@@ -0,0 +1,195 @@
+package internal_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+)
+
+// smtpServer is a minimal SMTP stand-in that records one session.
+type smtpServer struct {
+	addr      string
+	tlsConfig *tls.Config // offered via STARTTLS when set
+	// the session, readable once done is closed
+	auth     string
+	from     string
+	rcpts    []string
+	data     string
+	startTLS bool
+	done     chan struct{}
+}
+
+func newSMTPServer(t *testing.T, tlsConfig *tls.Config) *smtpServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	s := &smtpServer{addr: l.Addr().String(), tlsConfig: tlsConfig, done: make(chan struct{})}
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer close(s.done)
+		s.serve(conn)
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), conn
+	reply := func(line string) { _, _ = io.WriteString(w, line+"\r\n") }
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch cmd {
+		case "EHLO":
+			reply("250-localhost")
+			if s.tlsConfig != nil && !s.startTLS {
+				reply("250-STARTTLS")
+			}
+			reply("250 AUTH PLAIN")
+		case "STARTTLS":
+			reply("220 ready")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			s.startTLS = true
+			conn, r, w = tlsConn, bufio.NewReader(tlsConn), tlsConn
+		case "AUTH":
+			creds, _ := base64.StdEncoding.DecodeString(strings.Fields(line)[2])
+			s.auth = string(creds)
+			reply("235 ok")
+		case "MAIL":
+			s.from = line
+			reply("250 ok")
+		case "RCPT":
+			s.rcpts = append(s.rcpts, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var sb strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil || l == ".\r\n" {
+					break
+				}
+				sb.WriteString(l)
+			}
+			s.data = sb.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unknown command")
+		}
+	}
+}
+
+func (s *smtpServer) config(t *testing.T) internal.EmailConfig {
+	t.Helper()
+	host, port, _ := net.SplitHostPort(s.addr)
+	p, _ := strconv.Atoi(port)
+	return internal.EmailConfig{Host: host, Port: p, From: "bot@example.com", To: []string{"me@example.com", "team@example.com"}}
+}
+
+func TestEmailChannel(t *testing.T) {
+	// borrow the certificate of a TLS test server for STARTTLS
+	tlsSrv := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(tlsSrv.Close)
+	srv := newSMTPServer(t, tlsSrv.TLS)
+
+	conf := srv.config(t)
+	conf.Username, conf.Password = "bot", "secret"
+	conf.StartTLS = true
+	conf.TLSConfig = tlsSrv.Client().Transport.(*http.Transport).TLSClientConfig
+
+	if err := internal.NewEmailChannel(conf, nil).Send(context.Background(), testDigest()); err != nil {
+		t.Fatal(err)
+	}
+	<-srv.done
+
+	if !srv.startTLS {
+		t.Error("expected the connection to be upgraded with STARTTLS")
+	}
+	if srv.auth != "\x00bot\x00secret" {
+		t.Errorf("expected PLAIN auth, got %q", srv.auth)
+	}
+	if srv.from != "MAIL FROM:<bot@example.com>" || len(srv.rcpts) != 2 {
+		t.Errorf("unexpected envelope %q %q", srv.from, srv.rcpts)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(srv.data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if subject != "Todoist tasks for Sun, Jan 11" {
+		t.Errorf("unexpected subject %q", subject)
+	}
+	if got := msg.Header.Get("To"); got != "me@example.com, team@example.com" {
+		t.Errorf("unexpected To %q", got)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("expected multipart/alternative, got %q", mediaType)
+	}
+
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(p)
+		mediaType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[mediaType] = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+	if plain := parts["text/plain"]; !strings.Contains(plain, "📁 Work (2)\n- 🔴 Fix <prod> & deploy\n") {
+		t.Errorf("expected plain text grouped by project, got %q", plain)
+	}
+	html := parts["text/html"]
+	if !strings.Contains(html, `<a href="https://app.todoist.com/app/task/1"><b>Fix &lt;prod&gt; &amp; deploy</b></a>`) {
+		t.Errorf("expected escaped and linked html, got %q", html)
+	}
+}
+
+func TestEmailChannel_StartTLSRequired(t *testing.T) {
+	srv := newSMTPServer(t, nil)
+
+	conf := srv.config(t)
+	conf.StartTLS = true
+	if err := internal.NewEmailChannel(conf, nil).Send(context.Background(), testDigest()); err == nil {
+		t.Error("expected error when the server does not offer STARTTLS")
+	}
+}
@@ -0,0 +1,8 @@
+package internal
+
+import tele "gopkg.in/telebot.v3"
+
+// HandleVacation exposes the /vacation command to the tests.
+func (b *Bot) HandleVacation(c tele.Context) error {
+	return b.handleVacation(c)
+}
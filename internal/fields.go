@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+const (
+	FieldTime        = "time"
+	FieldLabels      = "labels"
+	FieldDescription = "description"
+	FieldProject     = "project"
+)
+
+// TaskFields selects the optional details shown on a task line.
+type TaskFields struct {
+	// Time shows the due time of timed tasks.
+	Time bool
+	// Labels shows labels, except the time labels, as #tags.
+	Labels bool
+	// Description shows the first line of the description below the task.
+	Description bool
+	// Project shows the project name. Requires a catalog.
+	Project bool
+
+	// MaxContent and MaxDescription truncate the task content and description to that many characters.
+	// Zero disables truncation.
+	MaxContent     int
+	MaxDescription int
+}
+
+// ParseTaskFields parses a comma separated list of fields, e.g. "time,labels".
+func ParseTaskFields(s string) (TaskFields, error) {
+	var res TaskFields
+	for _, f := range strings.Split(s, ",") {
+		switch strings.ToLower(strings.TrimSpace(f)) {
+		case "":
+			continue
+		case FieldTime:
+			res.Time = true
+		case FieldLabels:
+			res.Labels = true
+		case FieldDescription:
+			res.Description = true
+		case FieldProject:
+			res.Project = true
+		default:
+			return TaskFields{}, fmt.Errorf("unknown task field %q, expected %s, %s, %s or %s",
+				f, FieldTime, FieldLabels, FieldDescription, FieldProject)
+		}
+	}
+	return res, nil
+}
+
+// taskTags returns the labels of a task as tags, leaving out time labels which only control reveal.
+// Spaces are replaced with underscores, so that Telegram recognizes the whole label as a hashtag.
+func taskTags(task todoist.Task) []string {
+	var res []string
+	skip := timeLabels(task)
+	for _, l := range task.Labels {
+		if skip[l] {
+			continue
+		}
+		res = append(res, strings.ReplaceAll(l, " ", "_"))
+	}
+	return res
+}
+
+// taskTime returns the due time of a timed task, e.g. "14:30", or an empty string.
+func taskTime(task todoist.Task, loc *time.Location) string {
+	t, ok := dueTime(task, loc)
+	if !ok {
+		return ""
+	}
+	return t.Format("15:04")
+}
+
+// firstLine returns the first non-blank line of s.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// clip truncates s to n characters with an ellipsis. Non-positive n leaves s as is.
+func clip(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	res, _ := truncate(n, s) //nolint:errcheck // n is positive
+	return res
+}
@@ -0,0 +1,298 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+// Rule selects names by glob patterns (see path.Match). Matching is case-insensitive
+// and a leading "@" is ignored, so "@waiting" and "waiting" are equivalent.
+// An empty Include admits every name; Exclude always wins over Include.
+type Rule struct {
+	Include []string
+	Exclude []string
+}
+
+func (r Rule) IsZero() bool {
+	return len(r.Include) == 0 && len(r.Exclude) == 0
+}
+
+func (r Rule) Validate() error {
+	for _, p := range append(append([]string{}, r.Include...), r.Exclude...) {
+		if _, err := path.Match(normalizePattern(p), ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether a single name passes the rule.
+func (r Rule) Allows(name string) bool {
+	return r.AllowsAny([]string{name})
+}
+
+// AllowsAny reports whether a set of names (e.g. task labels) passes the rule:
+// at least one name must match Include (if set) and none may match Exclude.
+func (r Rule) AllowsAny(names []string) bool {
+	for _, n := range names {
+		if matchAny(r.Exclude, n) {
+			return false
+		}
+	}
+	if len(r.Include) == 0 {
+		return true
+	}
+	for _, n := range names {
+		if matchAny(r.Include, n) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, p := range patterns {
+		if ok, _ := path.Match(normalizePattern(p), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizePattern(p string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(p), "@"))
+}
+
+// Catalog resolves Todoist project and section IDs into their metadata.
+// A nil Catalog is valid and resolves every ID to an empty name.
+type Catalog struct {
+	projects     map[string]todoist.Project
+	sections     map[string]todoist.Section
+	projectRanks map[string]int
+}
+
+func NewCatalog(projects []todoist.Project, sections []todoist.Section) *Catalog {
+	res := &Catalog{
+		projects: make(map[string]todoist.Project, len(projects)),
+		sections: make(map[string]todoist.Section, len(sections)),
+	}
+	for _, p := range projects {
+		res.projects[p.ID] = p
+	}
+	for _, s := range sections {
+		res.sections[s.ID] = s
+	}
+	res.projectRanks = rankProjects(projects)
+	return res
+}
+
+// rankProjects returns the position of each project in the Todoist sidebar:
+// siblings ordered by child_order, sub-projects right after their parent.
+func rankProjects(projects []todoist.Project) map[string]int {
+	children := make(map[string][]todoist.Project)
+	ids := make(map[string]bool, len(projects))
+	for _, p := range projects {
+		ids[p.ID] = true
+	}
+	for _, p := range projects {
+		parent := p.ParentID
+		if !ids[parent] {
+			parent = ""
+		}
+		children[parent] = append(children[parent], p)
+	}
+
+	res := make(map[string]int, len(projects))
+	var walk func(parent string)
+	walk = func(parent string) {
+		siblings := children[parent]
+		slices.SortStableFunc(siblings, func(a, b todoist.Project) int {
+			return cmp.Compare(a.ChildOrder, b.ChildOrder)
+		})
+		for _, p := range siblings {
+			res[p.ID] = len(res)
+			walk(p.ID)
+		}
+	}
+	walk("")
+	return res
+}
+
+// sectionOrder returns the position of a section within its project. Unknown sections go last.
+func (c *Catalog) sectionOrder(id string) int {
+	if c == nil {
+		return math.MaxInt
+	}
+	if s, ok := c.sections[id]; ok {
+		return s.SectionOrder
+	}
+	return math.MaxInt
+}
+
+// projectRank returns the sidebar position of a project. Unknown projects go last.
+func (c *Catalog) projectRank(id string) int {
+	if c == nil {
+		return math.MaxInt
+	}
+	if r, ok := c.projectRanks[id]; ok {
+		return r
+	}
+	return math.MaxInt
+}
+
+func (c *Catalog) ProjectName(id string) string {
+	if c == nil {
+		return ""
+	}
+	return c.projects[id].Name
+}
+
+// projectNames returns project names by ID.
+func (c *Catalog) projectNames() map[string]string {
+	if c == nil {
+		return nil
+	}
+	res := make(map[string]string, len(c.projects))
+	for id, p := range c.projects {
+		res[id] = p.Name
+	}
+	return res
+}
+
+func (c *Catalog) SectionName(id string) string {
+	if c == nil {
+		return ""
+	}
+	return c.sections[id].Name
+}
+
+type RecurringMode string
+
+const (
+	// RecurringShow lists recurring tasks like any other task.
+	RecurringShow RecurringMode = "show"
+	// RecurringHabits lists recurring tasks in a compact "Habits" line.
+	RecurringHabits RecurringMode = "habits"
+	// RecurringExclude never shows recurring tasks.
+	RecurringExclude RecurringMode = "exclude"
+)
+
+// FilterReason explains the filter decision about a task, see ExplainFilter.
+type FilterReason string
+
+const (
+	// FilterIncluded tasks are due today and pass every filter.
+	FilterIncluded FilterReason = "included"
+	// FilterEscalated tasks are included because their deadline is within the deadline window.
+	FilterEscalated FilterReason = "deadline_escalated"
+
+	FilterNotDue         FilterReason = "not_due"
+	FilterIgnoredProject FilterReason = "ignored_project"
+	FilterMinPriority    FilterReason = "min_priority"
+	FilterLabels         FilterReason = "labels"
+	FilterSections       FilterReason = "sections"
+	FilterProjects       FilterReason = "projects"
+	// FilterRecurringHidden recurring tasks are excluded or hidden until RecurringFrom.
+	FilterRecurringHidden FilterReason = "recurring_hidden"
+	// FilterNotRevealed tasks are hidden until their time label or priority hour.
+	FilterNotRevealed FilterReason = "not_revealed"
+)
+
+// Included reports whether the task passes the filters.
+func (r FilterReason) Included() bool {
+	return r == FilterIncluded || r == FilterEscalated
+}
+
+// escalatedTasks returns the IDs of the tasks included because of a close deadline.
+func escalatedTasks(decisions []FilterDecision) map[string]bool {
+	res := make(map[string]bool)
+	for _, d := range decisions {
+		if d.Reason == FilterEscalated {
+			res[d.TaskID] = true
+		}
+	}
+	return res
+}
+
+// FilterDecision is the outcome of filtering a task.
+type FilterDecision struct {
+	TaskID string       `json:"task_id"`
+	Reason FilterReason `json:"reason"`
+}
+
+// FilterOptions configures FilterAndSortTasks.
+type FilterOptions struct {
+	// FilterByTime hides tasks until their time label or priority hour has passed.
+	FilterByTime     bool
+	IgnoreProjectIDs []string
+	// MinPriority hides tasks below the given priority. Zero keeps all priorities.
+	MinPriority Priority
+	// DeadlineWindow escalates tasks whose deadline is at most this far away (or already passed)
+	// to P1, even if they are not due today. Zero disables escalation.
+	DeadlineWindow time.Duration
+
+	// ExcludeRecurring drops recurring tasks (daily habits etc.) entirely.
+	ExcludeRecurring bool
+	// RecurringFrom (time since midnight) hides recurring tasks until that time of day.
+	// Only applied together with FilterByTime.
+	RecurringFrom time.Duration
+
+	Labels   Rule
+	Sections Rule
+	Projects Rule
+
+	Sort SortStrategy
+
+	// Catalog is required to match Sections and Projects rules by name and to sort by project.
+	Catalog *Catalog
+}
+
+// NeedsCatalog reports whether the options match on names that have to be fetched from Todoist.
+func (o FilterOptions) NeedsCatalog() bool {
+	return !o.Sections.IsZero() || !o.Projects.IsZero() || o.Sort == SortByProject
+}
+
+func (o FilterOptions) hidesRecurring(now time.Time) bool {
+	if o.ExcludeRecurring {
+		return true
+	}
+	return o.FilterByTime && timeOfDay(now) < o.RecurringFrom
+}
+
+func (o FilterOptions) allows(t todoist.Task) bool {
+	return o.rejects(t) == ""
+}
+
+// rejects returns why the project, priority and name rules drop a task, or an empty reason.
+func (o FilterOptions) rejects(t todoist.Task) FilterReason {
+	if slices.Contains(o.IgnoreProjectIDs, t.ProjectID) {
+		return FilterIgnoredProject
+	}
+	if Priority(t.Priority) < o.MinPriority {
+		return FilterMinPriority
+	}
+	if !o.Labels.IsZero() {
+		labels := t.Labels
+		if len(labels) == 0 {
+			labels = []string{""}
+		}
+		if !o.Labels.AllowsAny(labels) {
+			return FilterLabels
+		}
+	}
+	if !o.Sections.IsZero() && !o.Sections.Allows(o.Catalog.SectionName(t.SectionID)) {
+		return FilterSections
+	}
+	if !o.Projects.IsZero() && !o.Projects.Allows(o.Catalog.ProjectName(t.ProjectID)) {
+		return FilterProjects
+	}
+	return ""
+}
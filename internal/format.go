@@ -0,0 +1,152 @@
+package internal
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// Format is the markup used for rendered messages.
+type Format string
+
+const (
+	FormatPlain    Format = "plain"
+	FormatHTML     Format = "html"
+	FormatMarkdown Format = "markdown"
+)
+
+// TaskLinks selects how task titles link to the task in Todoist.
+type TaskLinks string
+
+const (
+	LinksNone TaskLinks = "none"
+	// LinksWeb links to the Todoist web app.
+	LinksWeb TaskLinks = "web"
+	// LinksApp uses the todoist:// scheme that opens the native app.
+	LinksApp TaskLinks = "app"
+)
+
+var formats = []Format{FormatPlain, FormatHTML, FormatMarkdown} //nolint:gochecknoglobals // constant list
+
+var markdownEscaper = strings.NewReplacer( //nolint:gochecknoglobals // stateless replacer
+	`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`, "~", `\~`, "`", "\\`",
+	">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`, "|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+)
+
+var markdownURLEscaper = strings.NewReplacer(`\`, `\\`, ")", `\)`) //nolint:gochecknoglobals // stateless replacer
+
+// Escape makes s safe to use as literal text in the format.
+func (f Format) Escape(s string) string {
+	switch f {
+	case FormatHTML:
+		return html.EscapeString(s)
+	case FormatMarkdown:
+		return markdownEscaper.Replace(s)
+	case FormatPlain:
+		return s
+	default:
+		return s
+	}
+}
+
+// Bold renders already escaped text in bold.
+func (f Format) Bold(s string) string {
+	switch f {
+	case FormatHTML:
+		return "<b>" + s + "</b>"
+	case FormatMarkdown:
+		return "*" + s + "*"
+	case FormatPlain:
+		return s
+	default:
+		return s
+	}
+}
+
+// Link renders already escaped text as a link to url. Plain text ignores the link.
+func (f Format) Link(s, url string) string {
+	if url == "" {
+		return s
+	}
+	switch f {
+	case FormatHTML:
+		return `<a href="` + html.EscapeString(url) + `">` + s + "</a>"
+	case FormatMarkdown:
+		return "[" + s + "](" + markdownURLEscaper.Replace(url) + ")"
+	case FormatPlain:
+		return s
+	default:
+		return s
+	}
+}
+
+// ParseMode returns the Telegram parse mode of the format.
+func (f Format) ParseMode() tele.ParseMode {
+	switch f {
+	case FormatHTML:
+		return tele.ModeHTML
+	case FormatMarkdown:
+		return tele.ModeMarkdownV2
+	case FormatPlain:
+		return tele.ModeDefault
+	default:
+		return tele.ModeDefault
+	}
+}
+
+// TaskURL returns the link to a task, or an empty string for LinksNone.
+func (l TaskLinks) TaskURL(taskID string) string {
+	switch l {
+	case LinksWeb:
+		return "https://app.todoist.com/app/task/" + taskID
+	case LinksApp:
+		return "todoist://task?id=" + taskID
+	case LinksNone:
+		return ""
+	default:
+		return ""
+	}
+}
+
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatPlain, nil
+	}
+	for _, f := range formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown message format %q, expected one of %v", s, formats)
+}
+
+func ParseTaskLinks(s string) (TaskLinks, error) {
+	switch l := TaskLinks(s); l {
+	case "":
+		return LinksNone, nil
+	case LinksNone, LinksWeb, LinksApp:
+		return l, nil
+	default:
+		return "", fmt.Errorf("unknown task links %q, expected %s, %s or %s", s, LinksNone, LinksWeb, LinksApp)
+	}
+}
+
+// formatFuncs returns the template functions producing markup in the format:
+// esc escapes a value, title renders a task title (bold for P1, linked if the task has a URL).
+func formatFuncs(f Format) template.FuncMap {
+	return template.FuncMap{
+		"esc": func(v any) string {
+			return f.Escape(fmt.Sprint(v))
+		},
+		"title": func(t TaskData) string {
+			res := f.Escape(t.Content)
+			if Priority(t.ShownPriority()) == P1 {
+				res = f.Bold(res)
+			}
+			return f.Link(res, t.URL)
+		},
+	}
+}
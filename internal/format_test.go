@@ -0,0 +1,60 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestRenderTasksMessage_Formats(t *testing.T) {
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "Fix *bold* & <b>tags</b>", Priority: 4, ProjectID: "p"},
+		{ID: "2", Content: "snake_case (v1.2)!", Priority: 3, ProjectID: "p"},
+	}
+	catalog := internal.NewCatalog([]todoist.Project{{ID: "p", Name: "R&D"}}, nil)
+
+	tests := []struct {
+		name     string
+		opts     internal.RenderOptions
+		expected string
+	}{
+		{
+			name: "plain ignores links",
+			opts: internal.RenderOptions{Format: internal.FormatPlain, Links: internal.LinksWeb},
+			expected: "Uncompleted tasks for today:\n" +
+				"- 🔴 Fix *bold* & <b>tags</b>\n" +
+				"- 🟠 snake_case (v1.2)!\n",
+		},
+		{
+			name: "html",
+			opts: internal.RenderOptions{Format: internal.FormatHTML, Links: internal.LinksWeb, GroupBy: internal.GroupByProject, Catalog: catalog},
+			expected: "Uncompleted tasks for today:\n\n" +
+				"📁 R&amp;D (2)\n" +
+				"- 🔴 <a href=\"https://app.todoist.com/app/task/1\"><b>Fix *bold* &amp; &lt;b&gt;tags&lt;/b&gt;</b></a>\n" +
+				"- 🟠 <a href=\"https://app.todoist.com/app/task/2\">snake_case (v1.2)!</a>\n",
+		},
+		{
+			name: "markdown",
+			opts: internal.RenderOptions{Format: internal.FormatMarkdown, Links: internal.LinksApp, GroupBy: internal.GroupByProject, Catalog: catalog},
+			expected: "Uncompleted tasks for today:\n\n" +
+				"📁 R&D \\(2\\)\n" +
+				"\\- 🔴 [*Fix \\*bold\\* & <b\\>tags</b\\>*](todoist://task?id=1)\n" +
+				"\\- 🟠 [snake\\_case \\(v1\\.2\\)\\!](todoist://task?id=2)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := singleMessage(internal.RenderTasksMessage(tasks, now, tt.opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, msg)
+			}
+		})
+	}
+}
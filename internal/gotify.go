@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GotifyChannel pushes digests to a Gotify server as an application.
+type GotifyChannel struct {
+	serverURL string
+	token     string
+	client    HTTPClient
+}
+
+// gotifyMessage is a Gotify message, see https://gotify.net/api-docs#/message/createMessage.
+type gotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// NewGotifyChannel returns a Gotify channel. token is the application token.
+func NewGotifyChannel(serverURL, token string, client HTTPClient) *GotifyChannel {
+	return &GotifyChannel{serverURL: strings.TrimSuffix(serverURL, "/"), token: token, client: client}
+}
+
+func (c *GotifyChannel) Name() string {
+	return "gotify"
+}
+
+func (c *GotifyChannel) Send(ctx context.Context, digest Digest) error {
+	msg := gotifyMessage{
+		Title:    pushTitle(digest),
+		Message:  pushBody(digest),
+		Priority: gotifyPriority(digest.Counts.topPriority()),
+		Extras: map[string]any{
+			"client::notification": map[string]any{"click": map[string]string{"url": todoistTodayURL}},
+		},
+	}
+
+	header := http.Header{"X-Gotify-Key": {c.token}}
+	resp, err := postJSON(ctx, c.client, c.serverURL+"/message", msg, header)
+	if err != nil {
+		return fmt.Errorf("post gotify message: %w", err)
+	}
+	if err := resp.checkStatus(); err != nil {
+		return fmt.Errorf("post gotify message: %w", err)
+	}
+	return nil
+}
+
+// gotifyPriority maps a task priority to a Gotify priority (0-10). The Android app alerts
+// from 4 and shows 8 and above as high priority.
+func gotifyPriority(p Priority) int {
+	switch p {
+	case P1:
+		return 10 //nolint:mnd // urgent
+	case P2:
+		return 8 //nolint:mnd // high
+	case P3:
+		return 5 //nolint:mnd // default
+	case P4:
+		return 2 //nolint:mnd // low, no sound
+	default:
+		return 5 //nolint:mnd // default
+	}
+}
@@ -0,0 +1,79 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+)
+
+type gotifyMessage struct {
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+	Extras   struct {
+		Notification struct {
+			Click struct {
+				URL string `json:"url"`
+			} `json:"click"`
+		} `json:"client::notification"`
+	} `json:"extras"`
+}
+
+func TestGotifyChannel(t *testing.T) {
+	srv, requests, bodies := recordingServer(t, http.StatusOK)
+
+	if err := internal.NewGotifyChannel(srv.URL+"/", "app-token", srv.Client()).Send(context.Background(), testDigest()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := (*requests)[0]
+	if req.URL.Path != "/message" || req.Header.Get("X-Gotify-Key") != "app-token" {
+		t.Errorf("expected an authorized post to /message, got %s %v", req.URL.Path, req.Header)
+	}
+	var msg gotifyMessage
+	if err := json.Unmarshal((*bodies)[0], &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Title != "Uncompleted tasks for today (4)" || msg.Priority != 10 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	if msg.Extras.Notification.Click.URL != "https://app.todoist.com/app/today" {
+		t.Errorf("unexpected click URL %q", msg.Extras.Notification.Click.URL)
+	}
+}
+
+func TestGotifyChannel_Priority(t *testing.T) {
+	tests := []struct {
+		priority int
+		want     int
+	}{
+		{priority: 4, want: 10},
+		{priority: 3, want: 8},
+		{priority: 2, want: 5},
+		{priority: 1, want: 2},
+	}
+	for _, tt := range tests {
+		srv, _, bodies := recordingServer(t, http.StatusOK)
+		if err := internal.NewGotifyChannel(srv.URL, "app-token", srv.Client()).Send(context.Background(), priorityDigest(tt.priority)); err != nil {
+			t.Fatal(err)
+		}
+		var msg gotifyMessage
+		if err := json.Unmarshal((*bodies)[0], &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Priority != tt.want {
+			t.Errorf("expected gotify priority %d for task priority %d, got %d", tt.want, tt.priority, msg.Priority)
+		}
+	}
+}
+
+func TestGotifyChannel_Error(t *testing.T) {
+	srv, _, _ := recordingServer(t, http.StatusUnauthorized)
+
+	if err := internal.NewGotifyChannel(srv.URL, "wrong", srv.Client()).Send(context.Background(), testDigest()); err == nil {
+		t.Error("expected error for rejected token")
+	}
+}
@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Lang is a language of bot messages.
+type Lang string
+
+const (
+	LangEnglish   Lang = "en"
+	LangUkrainian Lang = "uk"
+)
+
+// translations holds bot messages by language and key. A message is a fmt format, or a list of
+// plural forms for N: [one, other] in English and [one, few, many] in Ukrainian.
+var translations = map[Lang]map[string][]string{ //nolint:gochecknoglobals // message catalog
+	LangEnglish: {
+		"error.generic":      {"Something went wrong. Please try again later."},
+		"error.unauthorized": {"Unauthorized"},
+
+		"tasks.header":      {"Uncompleted tasks for today:"},
+		"tasks.pending":     {"Still pending:"},
+		"tasks.habits":      {"Habits:"},
+		"tasks.other":       {"Other"},
+		"tasks.empty":       {"No tasks for today! 🎉"},
+		"tasks.usage":       {"Usage: /tasks [%s]"},
+		"tasks.overbooked":  {"⚠️ Overbooked: %[1]s of estimated work, %[2]s left until %[3]s"},
+		"changes.new":       {"New since %s:"},
+		"changes.pending":   {"Still pending:"},
+		"changes.completed": {"Completed ✅"},
+		"nag.alert": {
+			"🔔 %d task still pending after %d+ reminders",
+			"🔔 %d tasks still pending after %d+ reminders",
+		},
+
+		"deadline.overdue": {"overdue"},
+		"deadline.hours":   {"%dh left"},
+		"deadline.days":    {"%dd %dh left"},
+
+		"duration.hours":   {"%dh"},
+		"duration.minutes": {"%dm"},
+		"duration.both":    {"%dh%02dm"},
+
+		"date.day": {"Mon, Jan 2"},
+
+		"agenda.tomorrow":       {"Tasks for tomorrow:"},
+		"agenda.tomorrow.empty": {"No tasks for tomorrow! 🎉"},
+		"agenda.week":           {"Tasks for the week ahead:"},
+		"agenda.week.empty":     {"No tasks for the week ahead! 🎉"},
+		"agenda.preview":        {"Tomorrow preview:"},
+
+		"summary.header":    {"📊 Done today: %d of %d"},
+		"summary.remaining": {"Still to do:"},
+		"summary.more":      {"+%d more"},
+
+		"email.subject": {"Todoist tasks for %s"},
+
+		"vacation.usage":     {"Usage: /vacation <from> <to> (YYYY-MM-DD), /vacation off"},
+		"vacation.none":      {"No vacation planned."},
+		"vacation.current":   {"Vacation: %s"},
+		"vacation.cancelled": {"Vacation cancelled, notifications are back on."},
+		"vacation.invalid":   {"Invalid dates: %s"},
+		"vacation.set":       {"Vacation set: %s. Enjoy! 🌴"},
+
+		"language.usage": {"Usage: /language [%s]"},
+		"language.set":   {"Language set to English."},
+	},
+	LangUkrainian: {
+		"error.generic":      {"Щось пішло не так. Спробуйте пізніше."},
+		"error.unauthorized": {"Доступ заборонено"},
+
+		"tasks.header":      {"Невиконані задачі на сьогодні:"},
+		"tasks.pending":     {"Досі не виконано:"},
+		"tasks.habits":      {"Звички:"},
+		"tasks.other":       {"Інше"},
+		"tasks.empty":       {"На сьогодні задач немає! 🎉"},
+		"tasks.usage":       {"Використання: /tasks [%s]"},
+		"tasks.overbooked":  {"⚠️ Перевантаження: заплановано %[1]s роботи, до %[3]s лишилось %[2]s"},
+		"changes.new":       {"Нове з %s:"},
+		"changes.pending":   {"Досі не виконано:"},
+		"changes.completed": {"Виконано ✅"},
+		"nag.alert": {
+			"🔔 %d задача досі не виконана після %d+ нагадувань",
+			"🔔 %d задачі досі не виконані після %d+ нагадувань",
+			"🔔 %d задач досі не виконано після %d+ нагадувань",
+		},
+
+		"deadline.overdue": {"прострочено"},
+		"deadline.hours":   {"лишилось %d год"},
+		"deadline.days":    {"лишилось %d дн %d год"},
+
+		"duration.hours":   {"%d год"},
+		"duration.minutes": {"%d хв"},
+		"duration.both":    {"%d год %02d хв"},
+
+		"date.day": {"Mon, 2 January"},
+
+		"agenda.tomorrow":       {"Задачі на завтра:"},
+		"agenda.tomorrow.empty": {"На завтра задач немає! 🎉"},
+		"agenda.week":           {"Задачі на тиждень:"},
+		"agenda.week.empty":     {"На тиждень задач немає! 🎉"},
+		"agenda.preview":        {"Завтра на вас чекає:"},
+
+		"summary.header":    {"📊 Виконано сьогодні: %d з %d"},
+		"summary.remaining": {"Залишилось:"},
+		"summary.more":      {"ще %d"},
+
+		"email.subject": {"Задачі Todoist на %s"},
+
+		"vacation.usage":     {"Використання: /vacation <з> <по> (РРРР-ММ-ДД), /vacation off"},
+		"vacation.none":      {"Відпустку не заплановано."},
+		"vacation.current":   {"Відпустка: %s"},
+		"vacation.cancelled": {"Відпустку скасовано, сповіщення знову увімкнені."},
+		"vacation.invalid":   {"Некоректні дати: %s"},
+		"vacation.set":       {"Відпустка: %s. Гарного відпочинку! 🌴"},
+
+		"language.usage": {"Використання: /language [%s]"},
+		"language.set":   {"Мову змінено на українську."},
+	},
+}
+
+// monthNames translates English month names in layouts without a day number, e.g. "January 2006",
+// where languages like Ukrainian use the nominative rather than the genitive case of timeNames.
+var monthNames = map[Lang]*strings.Replacer{ //nolint:gochecknoglobals // stateless replacers
+	LangUkrainian: strings.NewReplacer(
+		"January", "січень", "February", "лютий", "March", "березень", "April", "квітень",
+		"May", "травень", "June", "червень", "July", "липень", "August", "серпень",
+		"September", "вересень", "October", "жовтень", "November", "листопад", "December", "грудень",
+	),
+}
+
+// timeNames translates English month and weekday names produced by time.Format.
+// Full names go first, so that "Monday" is not translated as "Mon" + "day".
+var timeNames = map[Lang]*strings.Replacer{ //nolint:gochecknoglobals // stateless replacers
+	LangUkrainian: strings.NewReplacer(
+		"January", "січня", "February", "лютого", "March", "березня", "April", "квітня",
+		"May", "травня", "June", "червня", "July", "липня", "August", "серпня",
+		"September", "вересня", "October", "жовтня", "November", "листопада", "December", "грудня",
+		"Monday", "понеділок", "Tuesday", "вівторок", "Wednesday", "середа", "Thursday", "четвер",
+		"Friday", "пʼятниця", "Saturday", "субота", "Sunday", "неділя",
+		"Jan", "січ", "Feb", "лют", "Mar", "бер", "Apr", "кві", "Jun", "чер", "Jul", "лип",
+		"Aug", "сер", "Sep", "вер", "Oct", "жов", "Nov", "лис", "Dec", "гру",
+		"Mon", "пн", "Tue", "вт", "Wed", "ср", "Thu", "чт", "Fri", "пт", "Sat", "сб", "Sun", "нд",
+	),
+}
+
+var langs = []Lang{LangEnglish, LangUkrainian} //nolint:gochecknoglobals // constant list
+
+func ParseLang(s string) (Lang, error) {
+	if s == "" {
+		return LangEnglish, nil
+	}
+	for _, l := range langs {
+		if string(l) == strings.ToLower(s) {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unknown language %q, expected one of %s", s, joinLangs())
+}
+
+func joinLangs() string {
+	res := make([]string, len(langs))
+	for i, l := range langs {
+		res[i] = string(l)
+	}
+	return strings.Join(res, "|")
+}
+
+// T returns the translated message for key, formatted with args.
+func (l Lang) T(key string, args ...any) string {
+	forms := l.forms(key)
+	return sprintf(forms[0], args...)
+}
+
+// N returns the translated message for key in the plural form for n. n is the first format argument.
+func (l Lang) N(key string, n int, args ...any) string {
+	forms := l.forms(key)
+	form := min(l.pluralForm(n), len(forms)-1)
+	return sprintf(forms[form], append([]any{n}, args...)...)
+}
+
+func (l Lang) forms(key string) []string {
+	if forms, ok := translations[l][key]; ok {
+		return forms
+	}
+	if forms, ok := translations[LangEnglish][key]; ok {
+		return forms
+	}
+	return []string{key}
+}
+
+// pluralForm returns the index of the plural form for n.
+func (l Lang) pluralForm(n int) int {
+	switch l {
+	case LangUkrainian:
+		switch mod10, mod100 := n%10, n%100; {
+		case mod10 == 1 && mod100 != 11:
+			return 0
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return 1
+		default:
+			return 2 //nolint:mnd // "many" form
+		}
+	case LangEnglish:
+		fallthrough
+	default:
+		if n == 1 {
+			return 0
+		}
+		return 1
+	}
+}
+
+// FormatTime formats t with a Go layout, translating month and weekday names.
+func (l Lang) FormatTime(t time.Time, layout string) string {
+	res := t.Format(layout)
+	if r, ok := monthNames[l]; ok && !hasDay(layout) {
+		res = r.Replace(res)
+	}
+	if r, ok := timeNames[l]; ok {
+		res = r.Replace(res)
+	}
+	return res
+}
+
+// hasDay reports whether a Go layout shows the day of the month or year. The days are a week
+// apart, so that the weekday is the same.
+func hasDay(layout string) bool {
+	return time.Date(2001, time.January, 3, 0, 0, 0, 0, time.UTC).Format(layout) !=
+		time.Date(2001, time.January, 10, 0, 0, 0, 0, time.UTC).Format(layout)
+}
+
+// FormatDate formats the day of t, e.g. "Mon, Jan 2".
+func (l Lang) FormatDate(t time.Time) string {
+	return l.FormatTime(t, l.T("date.day"))
+}
+
+// FormatDuration renders a duration as hours and minutes, e.g. "2h", "45m" or "5h30m".
+func (l Lang) FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	h, m := int(d.Hours()), int(d.Minutes())%60 //nolint:mnd // minutes in hour
+	switch {
+	case h == 0:
+		return l.T("duration.minutes", m)
+	case m == 0:
+		return l.T("duration.hours", h)
+	default:
+		return l.T("duration.both", h, m)
+	}
+}
+
+func sprintf(format string, args ...any) string {
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,101 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestLang_N(t *testing.T) {
+	tests := []struct {
+		lang internal.Lang
+		n    int
+		want string
+	}{
+		{internal.LangEnglish, 1, "🔔 1 task still pending after 6+ reminders"},
+		{internal.LangEnglish, 2, "🔔 2 tasks still pending after 6+ reminders"},
+		{internal.LangUkrainian, 1, "🔔 1 задача досі не виконана після 6+ нагадувань"},
+		{internal.LangUkrainian, 3, "🔔 3 задачі досі не виконані після 6+ нагадувань"},
+		{internal.LangUkrainian, 5, "🔔 5 задач досі не виконано після 6+ нагадувань"},
+		{internal.LangUkrainian, 11, "🔔 11 задач досі не виконано після 6+ нагадувань"},
+		{internal.LangUkrainian, 21, "🔔 21 задача досі не виконана після 6+ нагадувань"},
+		{internal.LangUkrainian, 24, "🔔 24 задачі досі не виконані після 6+ нагадувань"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.lang.N("nag.alert", tt.n, 6); got != tt.want {
+			t.Errorf("%s N(%d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLang_FormatDate(t *testing.T) {
+	date := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	if got := internal.LangEnglish.FormatDate(date); got != "Mon, Mar 9" {
+		t.Errorf("expected English date, got %q", got)
+	}
+	if got := internal.LangUkrainian.FormatDate(date); got != "пн, 9 березня" {
+		t.Errorf("expected Ukrainian date, got %q", got)
+	}
+}
+
+func TestLang_FormatTime(t *testing.T) {
+	date := time.Date(2026, 1, 9, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		layout string
+		want   string
+	}{
+		{"2 January", "9 січня"},
+		{"Monday, 02 January 2006", "пʼятниця, 09 січня 2026"},
+		{"January 2006", "січень 2026"},
+		{"Jan 2006", "січ 2026"},
+	}
+
+	for _, tt := range tests {
+		if got := internal.LangUkrainian.FormatTime(date, tt.layout); got != tt.want {
+			t.Errorf("FormatTime(%q) = %q, want %q", tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	if lang, err := internal.ParseLang(""); err != nil || lang != internal.LangEnglish {
+		t.Errorf("expected English by default, got %q, %v", lang, err)
+	}
+	if lang, err := internal.ParseLang("UK"); err != nil || lang != internal.LangUkrainian {
+		t.Errorf("expected Ukrainian, got %q, %v", lang, err)
+	}
+	if _, err := internal.ParseLang("de"); err == nil {
+		t.Error("expected error for unknown language")
+	}
+}
+
+func TestRenderTasksMessage_Ukrainian(t *testing.T) {
+	now := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "Report", Priority: 4, Deadline: &todoist.TaskDeadline{Date: "2026-03-10"}},
+		{ID: "2", Content: "Gym", Priority: 1, Due: &todoist.TaskDue{Date: "2026-03-09", IsRecurring: true}},
+	}
+	workload := internal.Workload{Planned: 5 * time.Hour, Available: 90 * time.Minute, EndOfDay: now.Add(12 * time.Hour)}
+
+	msg, err := singleMessage(internal.RenderTasksMessage(tasks, now, internal.RenderOptions{
+		Habits:   true,
+		Workload: &workload,
+		Lang:     internal.LangUkrainian,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Невиконані задачі на сьогодні:\n" +
+		"- 🔴 Report ⏰ лишилось 1 дн 14 год\n" +
+		"\n" +
+		"Звички: Gym\n" +
+		"\n" +
+		"⚠️ Перевантаження: заплановано 5 год роботи, до 22:00 лишилось 1 год 30 хв\n"
+	if msg != want {
+		t.Errorf("unexpected message:\n%s\nwant:\n%s", msg, want)
+	}
+}
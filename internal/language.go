@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"fmt"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// lang returns the language of the chat, set with /language, or the configured default.
+func (b *Bot) lang(chatID int64) Lang {
+	res := b.conf.Language
+	b.store.View(func(state *State) {
+		if l, ok := state.Languages[chatID]; ok {
+			res = l
+		}
+	})
+	if res == "" {
+		return LangEnglish
+	}
+	return res
+}
+
+func (b *Bot) handleLanguage(c tele.Context) error {
+	chatID := c.Chat().ID
+	if c.Message().Payload == "" {
+		return c.Send(b.lang(chatID).T("language.usage", joinLangs()))
+	}
+
+	lang, err := ParseLang(c.Message().Payload)
+	if err != nil {
+		return c.Send(b.lang(chatID).T("language.usage", joinLangs()))
+	}
+
+	if err := b.store.Update(func(state *State) error {
+		if state.Languages == nil {
+			state.Languages = make(map[int64]Lang)
+		}
+		state.Languages[chatID] = lang
+		return nil
+	}); err != nil {
+		return fmt.Errorf("save language: %w", err)
+	}
+
+	return c.Send(lang.T("language.set"))
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// LiveMessage is the pinned message with today's tasks that scheduled notifications edit in place.
+type LiveMessage struct {
+	// Date is the day the message was sent, a new day starts a new message.
+	Date string `json:"date"`
+	// MessageIDs are the chunks of the message, the first one is pinned.
+	MessageIDs []int `json:"message_ids"`
+}
+
+// hasLiveMessage reports whether the chat has a live message of the day of now.
+func (b *Bot) hasLiveMessage(chatID int64, now time.Time) bool {
+	if !b.conf.LiveMessage {
+		return false
+	}
+	var res bool
+	b.store.View(func(state *State) {
+		live, ok := state.Live[chatID]
+		res = ok && live.Date == now.Format(time.DateOnly)
+	})
+	return res
+}
+
+// updateLiveMessage edits today's live message with msgs. Chunks that can be edited are edited,
+// extra chunks are sent after them and surplus ones are deleted. When a chunk can no longer be
+// edited, it is replaced together with the chunks after it, to keep them in order. A new day
+// starts a new pinned message.
+func (b *Bot) updateLiveMessage(ctx context.Context, chatID int64, now time.Time, msgs []string, format Format, silent bool) error {
+	var (
+		live   LiveMessage
+		exists bool
+	)
+	b.store.View(func(state *State) {
+		live, exists = state.Live[chatID]
+	})
+
+	today := now.Format(time.DateOnly)
+	var kept []int
+	if exists && live.Date == today {
+		kept = b.editMessages(ctx, chatID, live.MessageIDs, msgs, format)
+		for _, id := range live.MessageIDs[len(kept):] {
+			if err := b.messenger.Delete(ctx, chatID, id); err != nil && !errors.Is(err, ErrMessageNotFound) {
+				b.log.WarnContext(ctx, "failed to delete live message chunk", "chat_id", chatID, "message_id", id, "error", err)
+			}
+		}
+	}
+
+	sent, err := b.sendMessages(ctx, chatID, msgs[len(kept):], format, silent || len(kept) > 0)
+	if err != nil {
+		return err
+	}
+	ids := slices.Concat(kept, sent)
+
+	if len(kept) == 0 {
+		if exists && len(live.MessageIDs) > 0 && live.Date != today {
+			if err := b.messenger.Unpin(ctx, chatID, live.MessageIDs[0]); err != nil {
+				b.log.WarnContext(ctx, "failed to unpin previous live message", "chat_id", chatID, "error", err)
+			}
+		}
+		if err := b.messenger.Pin(ctx, chatID, ids[0]); err != nil {
+			// pinning needs admin rights in groups, the message is still updated without it
+			b.log.WarnContext(ctx, "failed to pin live message", "chat_id", chatID, "error", err)
+		}
+	}
+
+	if err := b.store.Update(func(state *State) error {
+		if state.Live == nil {
+			state.Live = make(map[int64]LiveMessage)
+		}
+		state.Live[chatID] = LiveMessage{Date: today, MessageIDs: ids}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("save live message: %w", err)
+	}
+
+	return nil
+}
+
+// editMessages replaces the text of message chunks in order, as long as there are both chunks
+// and messages, and returns the IDs of the chunks it edited.
+func (b *Bot) editMessages(ctx context.Context, chatID int64, ids []int, msgs []string, format Format) []int {
+	for i, id := range ids[:min(len(ids), len(msgs))] {
+		if err := b.messenger.Edit(ctx, chatID, id, Message{Text: msgs[i], Format: format}); err != nil {
+			b.log.WarnContext(ctx, "failed to edit live message chunk, sending a new one", "chat_id", chatID,
+				"chunk", i+1, "error", err)
+			return ids[:i]
+		}
+	}
+	return ids[:min(len(ids), len(msgs))]
+}
@@ -0,0 +1,195 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// matrixHTMLFormat is the format of HTML formatted message bodies.
+const matrixHTMLFormat = "org.matrix.custom.html"
+
+// MatrixConfig configures the Matrix room the digest is sent to.
+type MatrixConfig struct {
+	// HomeserverURL is the client-server API base URL, e.g. https://matrix.org.
+	HomeserverURL string
+	AccessToken   string
+	RoomID        string
+	// Edit replaces today's previous message with m.replace instead of sending a new one.
+	Edit bool
+}
+
+// MatrixMessage is the last digest sent to a Matrix room.
+type MatrixMessage struct {
+	// Date is the day the message was sent, edits never cross days.
+	Date    string `json:"date"`
+	EventID string `json:"event_id"`
+}
+
+// MatrixChannel sends digests to a Matrix room, rendered with the task message template as HTML
+// with a plain text fallback.
+type MatrixChannel struct {
+	conf     MatrixConfig
+	template *MessageTemplate
+	store    StateStore
+	client   HTTPClient
+}
+
+type matrixContent struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+
+	NewContent *matrixContent   `json:"m.new_content,omitempty"`
+	RelatesTo  *matrixRelatesTo `json:"m.relates_to,omitempty"`
+}
+
+type matrixRelatesTo struct {
+	RelType string `json:"rel_type"`
+	EventID string `json:"event_id"`
+}
+
+// NewMatrixChannel returns a Matrix channel. A nil template uses the built-in one. The store keeps
+// the last event per room for edits.
+func NewMatrixChannel(conf MatrixConfig, template *MessageTemplate, store StateStore, client HTTPClient) *MatrixChannel {
+	if template == nil {
+		template = tasksTemplate
+	}
+	conf.HomeserverURL = strings.TrimSuffix(conf.HomeserverURL, "/")
+	return &MatrixChannel{conf: conf, template: template, store: store, client: client}
+}
+
+func (c *MatrixChannel) Name() string {
+	return "matrix"
+}
+
+func (c *MatrixChannel) Send(ctx context.Context, digest Digest) error {
+	content, err := c.content(digest)
+	if err != nil {
+		return fmt.Errorf("render matrix message: %w", err)
+	}
+
+	today := digest.Now.Format(time.DateOnly)
+	if prev, ok := c.previous(today); ok {
+		_, err := c.sendEvent(ctx, digest.RunID+"-edit", matrixEdit(content, prev.EventID))
+		if err == nil {
+			return nil
+		}
+		if !matrixCannotEdit(err) {
+			return fmt.Errorf("edit matrix message: %w", err)
+		}
+		// the event can no longer be edited, e.g. it was redacted; start a new message
+	}
+
+	eventID, err := c.sendEvent(ctx, digest.RunID, content)
+	if err != nil {
+		return fmt.Errorf("send matrix message: %w", err)
+	}
+	if !c.conf.Edit {
+		return nil
+	}
+	return c.store.Update(func(state *State) error {
+		if state.Matrix == nil {
+			state.Matrix = make(map[string]MatrixMessage)
+		}
+		state.Matrix[c.conf.RoomID] = MatrixMessage{Date: today, EventID: eventID}
+		return nil
+	})
+}
+
+// previous returns today's message to edit, if editing is enabled.
+func (c *MatrixChannel) previous(today string) (MatrixMessage, bool) {
+	if !c.conf.Edit {
+		return MatrixMessage{}, false
+	}
+	var (
+		res MatrixMessage
+		ok  bool
+	)
+	c.store.View(func(state *State) {
+		res, ok = state.Matrix[c.conf.RoomID]
+	})
+	return res, ok && res.Date == today && res.EventID != ""
+}
+
+// content renders the digest like a Telegram message. Telegram HTML breaks lines with newlines,
+// Matrix HTML needs <br>.
+func (c *MatrixChannel) content(d Digest) (matrixContent, error) {
+	plain, err := c.template.execute(FormatPlain, d.Lang, d.MessageData)
+	if err != nil {
+		return matrixContent{}, fmt.Errorf("render plain text: %w", err)
+	}
+	html, err := c.template.execute(FormatHTML, d.Lang, d.MessageData)
+	if err != nil {
+		return matrixContent{}, fmt.Errorf("render html: %w", err)
+	}
+	return matrixContent{
+		MsgType:       "m.text",
+		Body:          strings.TrimSpace(plain),
+		Format:        matrixHTMLFormat,
+		FormattedBody: strings.ReplaceAll(strings.TrimSpace(html), "\n", "<br>"),
+	}, nil
+}
+
+// matrixEdit wraps content into an m.replace edit of eventID. Clients without edit support show
+// the fallback body, marked with "* " by convention.
+func matrixEdit(content matrixContent, eventID string) matrixContent {
+	newContent := content
+	return matrixContent{
+		MsgType:       content.MsgType,
+		Body:          "* " + content.Body,
+		Format:        content.Format,
+		FormattedBody: "* " + content.FormattedBody,
+		NewContent:    &newContent,
+		RelatesTo:     &matrixRelatesTo{RelType: "m.replace", EventID: eventID},
+	}
+}
+
+// matrixCannotEdit reports whether an edit failed because of the edited event, e.g. it is gone or
+// was redacted. Other errors, like a bad token or rate limiting, would fail a new message as well.
+func matrixCannotEdit(err error) bool {
+	var status statusError
+	if !errors.As(err, &status) {
+		return false
+	}
+	var body struct {
+		ErrCode string `json:"errcode"`
+	}
+	if json.Unmarshal(status.Body, &body) == nil && body.ErrCode == "M_NOT_FOUND" {
+		return true
+	}
+	return status.StatusCode == http.StatusBadRequest
+}
+
+// sendEvent sends an m.room.message event and returns its ID. The transaction ID makes retries idempotent.
+func (c *MatrixChannel) sendEvent(ctx context.Context, txnID string, content matrixContent) (string, error) {
+	endpoint := c.conf.HomeserverURL + "/_matrix/client/v3/rooms/" + url.PathEscape(c.conf.RoomID) +
+		"/send/m.room.message/" + url.PathEscape(txnID)
+	body, err := json.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("encode event: %w", err)
+	}
+	header := http.Header{"Authorization": {"Bearer " + c.conf.AccessToken}}
+
+	resp, err := doRequest(ctx, c.client, http.MethodPut, endpoint, "application/json", body, header)
+	if err != nil {
+		return "", err
+	}
+	if err := resp.checkStatus(); err != nil {
+		return "", err
+	}
+
+	var res struct {
+		EventID string `json:"event_id"`
+	}
+	if err := json.Unmarshal(resp.Body, &res); err != nil {
+		return "", fmt.Errorf("decode response: %w", err)
+	}
+	return res.EventID, nil
+}
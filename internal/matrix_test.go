@@ -0,0 +1,182 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+)
+
+type matrixEvent struct {
+	path    string
+	auth    string
+	content struct {
+		MsgType       string `json:"msgtype"`
+		Body          string `json:"body"`
+		Format        string `json:"format"`
+		FormattedBody string `json:"formatted_body"`
+		NewContent    *struct {
+			Body string `json:"body"`
+		} `json:"m.new_content"`
+		RelatesTo *struct {
+			RelType string `json:"rel_type"`
+			EventID string `json:"event_id"`
+		} `json:"m.relates_to"`
+	}
+}
+
+// matrixServer is a homeserver stand-in that numbers events $1, $2, ... and rejects edits
+// of the event IDs in rejectEdits.
+func matrixServer(t *testing.T, rejectEdits ...string) (*httptest.Server, *[]matrixEvent) {
+	t.Helper()
+	var events []matrixEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		e := matrixEvent{path: r.Method + " " + r.URL.Path, auth: r.Header.Get("Authorization")}
+		if err := json.Unmarshal(body, &e.content); err != nil {
+			t.Error(err)
+		}
+		events = append(events, e)
+		if e.content.RelatesTo != nil {
+			for _, id := range rejectEdits {
+				if e.content.RelatesTo.EventID == id {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"errcode": "M_UNKNOWN"}`))
+					return
+				}
+			}
+		}
+		_, _ = fmt.Fprintf(w, `{"event_id": "$%d"}`, len(events))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &events
+}
+
+func newMatrixChannel(t *testing.T, srv *httptest.Server, edit bool) *internal.MatrixChannel {
+	t.Helper()
+	store, err := internal.OpenFileStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := internal.MatrixConfig{HomeserverURL: srv.URL + "/", AccessToken: "syt_token", RoomID: "!room:example.org", Edit: edit}
+	return internal.NewMatrixChannel(conf, nil, store, srv.Client())
+}
+
+func TestMatrixChannel(t *testing.T) {
+	srv, events := matrixServer(t)
+
+	if err := newMatrixChannel(t, srv, false).Send(context.Background(), testDigest()); err != nil {
+		t.Fatal(err)
+	}
+
+	e := (*events)[0]
+	if e.path != "PUT /_matrix/client/v3/rooms/!room:example.org/send/m.room.message/run-1" || e.auth != "Bearer syt_token" {
+		t.Errorf("unexpected request %s %q", e.path, e.auth)
+	}
+	if e.content.MsgType != "m.text" || e.content.Format != "org.matrix.custom.html" {
+		t.Errorf("expected an HTML text message, got %+v", e.content)
+	}
+	if !strings.HasPrefix(e.content.Body, "Uncompleted tasks for today:\n\n📁 Work (2)\n- 🔴 Fix <prod> & deploy") {
+		t.Errorf("unexpected plain body %q", e.content.Body)
+	}
+	html := `📁 Work (2)<br>- 🔴 <a href="https://app.todoist.com/app/task/1"><b>Fix &lt;prod&gt; &amp; deploy</b></a>`
+	if !strings.Contains(e.content.FormattedBody, html) {
+		t.Errorf("expected formatted body with %q, got %q", html, e.content.FormattedBody)
+	}
+	if e.content.RelatesTo != nil {
+		t.Errorf("expected a new message, got an edit of %+v", e.content.RelatesTo)
+	}
+}
+
+func TestMatrixChannel_Edit(t *testing.T) {
+	srv, events := matrixServer(t, "$3")
+	channel := newMatrixChannel(t, srv, true)
+	digest := testDigest()
+	send := func(runID string) {
+		t.Helper()
+		digest.RunID = runID
+		if err := channel.Send(context.Background(), digest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	send("run-1")
+	send("run-2")
+	edit := (*events)[1]
+	if edit.content.RelatesTo == nil || edit.content.RelatesTo.RelType != "m.replace" || edit.content.RelatesTo.EventID != "$1" {
+		t.Fatalf("expected an m.replace edit of $1, got %+v", edit.content.RelatesTo)
+	}
+	if !strings.HasPrefix(edit.content.Body, "* ") || edit.content.NewContent == nil || strings.HasPrefix(edit.content.NewContent.Body, "* ") {
+		t.Errorf("expected a fallback body and the new content, got %+v", edit.content)
+	}
+
+	// a new day starts a new message
+	digest.Now = digest.Now.AddDate(0, 0, 1)
+	send("run-3")
+	if (*events)[2].content.RelatesTo != nil {
+		t.Errorf("expected a new message on a new day, got an edit")
+	}
+
+	// $3 can not be edited, a new message replaces it
+	send("run-4")
+	if len(*events) != 5 || (*events)[4].content.RelatesTo != nil {
+		t.Fatalf("expected a new message after the rejected edit, got %d events", len(*events))
+	}
+	send("run-5")
+	if got := (*events)[5].content.RelatesTo; got == nil || got.EventID != "$5" {
+		t.Errorf("expected edits of the new message, got %+v", got)
+	}
+}
+
+func TestMatrixChannel_EditError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		fallback bool
+	}{
+		{"not found", http.StatusNotFound, `{"errcode": "M_NOT_FOUND"}`, true},
+		{"redacted", http.StatusBadRequest, `{"errcode": "M_UNKNOWN"}`, true},
+		{"unauthorized", http.StatusUnauthorized, `{"errcode": "M_UNKNOWN_TOKEN"}`, false},
+		{"forbidden", http.StatusForbidden, `{"errcode": "M_FORBIDDEN"}`, false},
+		{"rate limited", http.StatusTooManyRequests, `{"errcode": "M_LIMIT_EXCEEDED"}`, false},
+		{"server error", http.StatusBadGateway, ``, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent++
+				if strings.Contains(r.URL.Path, "-edit") {
+					w.WriteHeader(tt.status)
+					_, _ = w.Write([]byte(tt.body))
+					return
+				}
+				_, _ = fmt.Fprintf(w, `{"event_id": "$%d"}`, sent)
+			}))
+			t.Cleanup(srv.Close)
+			channel := newMatrixChannel(t, srv, true)
+			digest := testDigest()
+
+			digest.RunID = "run-1"
+			if err := channel.Send(context.Background(), digest); err != nil {
+				t.Fatal(err)
+			}
+			digest.RunID = "run-2"
+			err := channel.Send(context.Background(), digest)
+			if tt.fallback && (err != nil || sent != 3) {
+				t.Errorf("expected a new message after the failed edit, got %d requests, %v", sent, err)
+			}
+			if !tt.fallback && (err == nil || sent != 2) {
+				t.Errorf("expected the edit error without a new message, got %d requests, %v", sent, err)
+			}
+		})
+	}
+}
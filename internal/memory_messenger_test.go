@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// MemoryMessenger is an in-memory Messenger for the bot tests. It keeps the current messages of every chat.
+type MemoryMessenger struct {
+	mx       sync.Mutex
+	lastID   int
+	messages map[int64][]MemoryMessage
+}
+
+// MemoryMessage is a message as currently shown in a chat.
+type MemoryMessage struct {
+	Message
+	ID     int
+	Edits  int
+	Pinned bool
+}
+
+func NewMemoryMessenger() *MemoryMessenger {
+	return &MemoryMessenger{messages: make(map[int64][]MemoryMessage)}
+}
+
+// Messages returns the messages of a chat in the order they were sent, without the deleted ones.
+func (m *MemoryMessenger) Messages(chatID int64) []MemoryMessage {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+	return slices.Clone(m.messages[chatID])
+}
+
+func (m *MemoryMessenger) Send(_ context.Context, chatID int64, msg Message) (int, error) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.lastID++
+	m.messages[chatID] = append(m.messages[chatID], MemoryMessage{Message: msg, ID: m.lastID})
+	return m.lastID, nil
+}
+
+func (m *MemoryMessenger) Edit(_ context.Context, chatID int64, messageID int, msg Message) error {
+	return m.update(chatID, messageID, func(stored *MemoryMessage) {
+		if stored.Text != msg.Text || stored.Format != msg.Format {
+			stored.Text, stored.Format = msg.Text, msg.Format
+			stored.Edits++
+		}
+	})
+}
+
+func (m *MemoryMessenger) Delete(_ context.Context, chatID int64, messageID int) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	msgs := m.messages[chatID]
+	i := slices.IndexFunc(msgs, func(msg MemoryMessage) bool { return msg.ID == messageID })
+	if i < 0 {
+		return fmt.Errorf("delete message %d: %w", messageID, ErrMessageNotFound)
+	}
+	m.messages[chatID] = slices.Delete(msgs, i, i+1)
+	return nil
+}
+
+func (m *MemoryMessenger) Pin(_ context.Context, chatID int64, messageID int) error {
+	return m.update(chatID, messageID, func(stored *MemoryMessage) {
+		stored.Pinned = true
+	})
+}
+
+func (m *MemoryMessenger) Unpin(_ context.Context, chatID int64, messageID int) error {
+	return m.update(chatID, messageID, func(stored *MemoryMessage) {
+		stored.Pinned = false
+	})
+}
+
+func (m *MemoryMessenger) update(chatID int64, messageID int, fn func(stored *MemoryMessage)) error {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	msgs := m.messages[chatID]
+	i := slices.IndexFunc(msgs, func(msg MemoryMessage) bool { return msg.ID == messageID })
+	if i < 0 {
+		return fmt.Errorf("message %d: %w", messageID, ErrMessageNotFound)
+	}
+	fn(&msgs[i])
+	return nil
+}
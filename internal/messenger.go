@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"errors"
+)
+
+// ErrMessageNotFound is returned by a Messenger for a message that does not exist (anymore).
+var ErrMessageNotFound = errors.New("message not found")
+
+// Message is an outgoing message.
+type Message struct {
+	// Text is the message in Format markup.
+	Text   string
+	Format Format
+	// Silent delivers the message without a notification.
+	Silent bool
+	// Photo is a PNG image sent with Text as its caption.
+	Photo []byte
+}
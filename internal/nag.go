@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+// NotifiedTask tracks how many scheduled notifications included a task.
+type NotifiedTask struct {
+	Count int `json:"count"`
+	// Due is the due date the task had when last notified. Rescheduling a task resets its count.
+	Due string `json:"due"`
+}
+
+// NextNotificationCounts returns the notification counts after sent tasks were notified.
+// Tasks no longer open (completed or deleted) are dropped, so the state does not grow forever.
+func NextNotificationCounts(prev map[string]NotifiedTask, sent, open []todoist.Task) map[string]NotifiedTask {
+	res := make(map[string]NotifiedTask, len(open))
+	// the due date is only compared with its previous value, any fixed location works
+	for _, t := range open {
+		if n, ok := prev[t.ID]; ok && n.Due == dueDate(t, time.UTC) {
+			res[t.ID] = n
+		}
+	}
+	for _, t := range sent {
+		n := res[t.ID]
+		res[t.ID] = NotifiedTask{Count: n.Count + 1, Due: dueDate(t, time.UTC)}
+	}
+	return res
+}
+
+// naggedTasks returns notification counts of tasks that were notified more than threshold times.
+// A zero threshold disables nagging.
+func naggedTasks(counts map[string]NotifiedTask, tasks []todoist.Task, threshold int) map[string]int {
+	if threshold <= 0 {
+		return nil
+	}
+	res := make(map[string]int)
+	for _, t := range tasks {
+		if n := counts[t.ID]; n.Count > threshold {
+			res[t.ID] = n.Count
+		}
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestNextNotificationCounts(t *testing.T) {
+	prev := map[string]internal.NotifiedTask{
+		"1": {Count: 5, Due: "2026-01-11"},
+		"2": {Count: 3, Due: "2026-01-10"},
+		"3": {Count: 2, Due: "2026-01-11"},
+		"4": {Count: 9, Due: "2026-01-11"},
+	}
+	open := []todoist.Task{
+		{ID: "1", Due: &todoist.TaskDue{Date: "2026-01-11"}},
+		{ID: "2", Due: &todoist.TaskDue{Date: "2026-01-11"}}, // rescheduled
+		{ID: "3", Due: &todoist.TaskDue{Date: "2026-01-11"}}, // not notified this time
+		{ID: "5", Due: &todoist.TaskDue{Date: "2026-01-11"}}, // new
+	}
+	sent := []todoist.Task{open[0], open[1], open[3]}
+
+	got := internal.NextNotificationCounts(prev, sent, open)
+
+	expected := map[string]int{"1": 6, "2": 1, "3": 2, "5": 1}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d tracked tasks, got %v", len(expected), got)
+	}
+	for id, count := range expected {
+		if got[id].Count != count {
+			t.Errorf("expected task %s count %d, got %d", id, count, got[id].Count)
+		}
+	}
+}
+
+func TestRenderTasksMessage_Nagged(t *testing.T) {
+	tasks := []todoist.Task{
+		{ID: "1", Content: "fresh", Priority: 4},
+		{ID: "2", Content: "ignored", Priority: 3},
+	}
+
+	msg, err := singleMessage(internal.RenderTasksMessage(tasks, time.Now(), internal.RenderOptions{Nagged: map[string]int{"2": 7}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Still pending:\n- 🟠 ignored 🔁 7×\n\nUncompleted tasks for today:\n- 🔴 fresh\n"
+	if msg != expected {
+		t.Errorf("expected message %q, got %q", expected, msg)
+	}
+
+	msg, err = singleMessage(internal.RenderTasksMessage(tasks[1:], time.Now(), internal.RenderOptions{Nagged: map[string]int{"2": 7}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(msg, "Uncompleted") || !strings.HasPrefix(msg, "Still pending:") {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// NtfyChannel publishes digests to an ntfy topic.
+type NtfyChannel struct {
+	topicURL string
+	token    string
+	client   HTTPClient
+}
+
+// ntfyMessage is an ntfy JSON publish request, see https://docs.ntfy.sh/publish/#publish-as-json.
+type ntfyMessage struct {
+	Topic    string `json:"topic"`
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+	Priority int    `json:"priority"`
+	Click    string `json:"click"`
+}
+
+// NewNtfyChannel returns an ntfy channel for a topic URL, e.g. https://ntfy.sh/my-tasks.
+// token is an optional access token.
+func NewNtfyChannel(topicURL, token string, client HTTPClient) *NtfyChannel {
+	return &NtfyChannel{topicURL: topicURL, token: token, client: client}
+}
+
+func (c *NtfyChannel) Name() string {
+	return "ntfy"
+}
+
+func (c *NtfyChannel) Send(ctx context.Context, digest Digest) error {
+	server, topic, err := splitNtfyURL(c.topicURL)
+	if err != nil {
+		return err
+	}
+	msg := ntfyMessage{
+		Topic:    topic,
+		Title:    pushTitle(digest),
+		Message:  pushBody(digest),
+		Priority: ntfyPriority(digest.Counts.topPriority()),
+		Click:    todoistTodayURL,
+	}
+
+	var header http.Header
+	if c.token != "" {
+		header = http.Header{"Authorization": {"Bearer " + c.token}}
+	}
+	resp, err := postJSON(ctx, c.client, server, msg, header)
+	if err != nil {
+		return fmt.Errorf("publish ntfy message: %w", err)
+	}
+	if err := resp.checkStatus(); err != nil {
+		return fmt.Errorf("publish ntfy message: %w", err)
+	}
+	return nil
+}
+
+// splitNtfyURL splits a topic URL into the server URL JSON messages are published to and the topic.
+func splitNtfyURL(topicURL string) (string, string, error) {
+	u, err := url.Parse(topicURL)
+	if err != nil {
+		return "", "", fmt.Errorf("parse ntfy topic URL: %w", err)
+	}
+	p := strings.TrimSuffix(u.Path, "/")
+	topic := path.Base(p)
+	if u.Scheme == "" || u.Host == "" || topic == "." || topic == "/" {
+		return "", "", errors.New("ntfy topic URL must look like https://ntfy.sh/<topic>")
+	}
+	u.Path = path.Dir(p)
+	return u.String(), topic, nil
+}
+
+// ntfyPriority maps a task priority to an ntfy priority, from 5 (urgent) for P1 to 2 (low) for P4.
+func ntfyPriority(p Priority) int {
+	switch p {
+	case P1:
+		return 5 //nolint:mnd // urgent
+	case P2:
+		return 4 //nolint:mnd // high
+	case P3:
+		return 3 //nolint:mnd // default
+	case P4:
+		return 2 //nolint:mnd // low
+	default:
+		return 3 //nolint:mnd // default
+	}
+}
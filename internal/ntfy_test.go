@@ -0,0 +1,93 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+// priorityDigest returns a digest with a single task of the given Todoist API priority.
+func priorityDigest(priority int) internal.Digest {
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+	tasks := []todoist.Task{{ID: "1", Content: "report", Priority: priority}}
+	return internal.NewDigest(tasks, now, internal.RenderOptions{}, "run-1")
+}
+
+func TestNtfyChannel(t *testing.T) {
+	srv, requests, bodies := recordingServer(t, http.StatusOK)
+
+	if err := internal.NewNtfyChannel(srv.URL+"/my-tasks", "tk_secret", srv.Client()).Send(context.Background(), testDigest()); err != nil {
+		t.Fatal(err)
+	}
+
+	req := (*requests)[0]
+	if req.URL.Path != "/" || req.Header.Get("Authorization") != "Bearer tk_secret" {
+		t.Errorf("expected an authorized JSON publish to the server root, got %s %v", req.URL.Path, req.Header)
+	}
+	var msg struct {
+		Topic    string `json:"topic"`
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+		Click    string `json:"click"`
+	}
+	if err := json.Unmarshal((*bodies)[0], &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Topic != "my-tasks" || msg.Title != "Uncompleted tasks for today (4)" || msg.Priority != 5 {
+		t.Errorf("unexpected message %+v", msg)
+	}
+	want := "Work\n🔴 Fix <prod> & deploy\n🔵 review\n\nHome\n🟠 laundry\n\nHabits: stretch"
+	if msg.Message != want {
+		t.Errorf("expected body %q, got %q", want, msg.Message)
+	}
+	if msg.Click != "https://app.todoist.com/app/today" {
+		t.Errorf("unexpected click URL %q", msg.Click)
+	}
+}
+
+func TestNtfyChannel_Priority(t *testing.T) {
+	tests := []struct {
+		priority int
+		want     int
+	}{
+		{priority: 4, want: 5},
+		{priority: 3, want: 4},
+		{priority: 2, want: 3},
+		{priority: 1, want: 2},
+	}
+	for _, tt := range tests {
+		srv, requests, bodies := recordingServer(t, http.StatusOK)
+		if err := internal.NewNtfyChannel(srv.URL+"/tasks", "", srv.Client()).Send(context.Background(), priorityDigest(tt.priority)); err != nil {
+			t.Fatal(err)
+		}
+		if auth := (*requests)[0].Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected no auth without a token, got %q", auth)
+		}
+		var msg struct {
+			Priority int `json:"priority"`
+		}
+		if err := json.Unmarshal((*bodies)[0], &msg); err != nil {
+			t.Fatal(err)
+		}
+		if msg.Priority != tt.want {
+			t.Errorf("expected ntfy priority %d for task priority %d, got %d", tt.want, tt.priority, msg.Priority)
+		}
+	}
+}
+
+func TestNtfyChannel_Error(t *testing.T) {
+	srv, _, _ := recordingServer(t, http.StatusForbidden)
+
+	if err := internal.NewNtfyChannel(srv.URL+"/tasks", "", srv.Client()).Send(context.Background(), testDigest()); err == nil {
+		t.Error("expected error for rejected publish")
+	}
+	if err := internal.NewNtfyChannel(srv.URL, "", srv.Client()).Send(context.Background(), testDigest()); err == nil {
+		t.Error("expected error for URL without topic")
+	}
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"net/http"
 	"time"
 
 	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
@@ -13,4 +14,32 @@ type Clock interface {
 
 type TodoistClient interface {
 	GetTasksLimit200(ctx context.Context, includeCompleted bool) ([]todoist.Task, error)
+	GetCompletedTasks(ctx context.Context, since, until time.Time) ([]todoist.Task, error)
+	GetProjects(ctx context.Context) ([]todoist.Project, error)
+	GetSections(ctx context.Context) ([]todoist.Section, error)
+}
+
+type StateStore interface {
+	View(fn func(state *State))
+	Update(fn func(state *State) error) error
+}
+
+// Messenger delivers messages to a chat. Message IDs are only meaningful to the Messenger that returned them.
+type Messenger interface {
+	Send(ctx context.Context, chatID int64, msg Message) (int, error)
+	// Edit replaces the text of a sent message. Editing a message to the same text is not an error.
+	Edit(ctx context.Context, chatID int64, messageID int, msg Message) error
+	Delete(ctx context.Context, chatID int64, messageID int) error
+	Pin(ctx context.Context, chatID int64, messageID int) error
+	Unpin(ctx context.Context, chatID int64, messageID int) error
+}
+
+// Channel delivers scheduled task digests to a target other than the Telegram chat.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, digest Digest) error
+}
+
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
 }
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+const defaultProfileName = "default"
+
+// Profile is a named set of notification settings that applies on selected weekdays
+// and, optionally, only within a date range (e.g. workdays vs. weekends).
+type Profile struct {
+	Name string
+	// Weekdays the profile applies on. Empty means every day.
+	Weekdays []time.Weekday
+	// From and To bound the profile to an inclusive date range (YYYY-MM-DD). Empty means unbounded.
+	From string
+	To   string
+
+	Schedule         string
+	FilterByTime     bool
+	IgnoreProjectIDs []string
+	QuietHours       *QuietHours
+}
+
+// Matches reports whether the profile applies on the date of t.
+func (p Profile) Matches(t time.Time) bool {
+	if len(p.Weekdays) > 0 && !slices.Contains(p.Weekdays, t.Weekday()) {
+		return false
+	}
+	date := t.Format(time.DateOnly)
+	if p.From != "" && date < p.From {
+		return false
+	}
+	if p.To != "" && date > p.To {
+		return false
+	}
+	return true
+}
+
+// QuietHours is a daily time window, which may span midnight, when scheduled notifications are not sent.
+type QuietHours struct {
+	From time.Duration
+	To   time.Duration
+}
+
+// ParseQuietHours parses a "HH:MM-HH:MM" window, e.g. "22:00-08:00".
+func ParseQuietHours(s string) (*QuietHours, error) {
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", s)
+	}
+	f, err := parseClock(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours %q: %w", s, err)
+	}
+	t, err := parseClock(to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quiet hours %q: %w", s, err)
+	}
+	return &QuietHours{From: f, To: t}, nil
+}
+
+// Contains reports whether t falls within the window. A nil window contains nothing.
+func (q *QuietHours) Contains(t time.Time) bool {
+	if q == nil {
+		return false
+	}
+	sinceMidnight := timeOfDay(t)
+	if q.From <= q.To {
+		return sinceMidnight >= q.From && sinceMidnight < q.To
+	}
+	return sinceMidnight >= q.From || sinceMidnight < q.To
+}
+
+// timeOfDay returns the time since midnight of t, truncated to minutes.
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+}
+
+// parseClock parses "HH:MM" into the duration since midnight.
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("parse time of day %q: %w", s, err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+type profileFile struct {
+	Profiles []struct {
+		Name             string   `json:"name"`
+		Weekdays         []string `json:"weekdays"`
+		From             string   `json:"from"`
+		To               string   `json:"to"`
+		Schedule         string   `json:"schedule"`
+		FilterByTime     *bool    `json:"filter_by_time"`
+		IgnoreProjectIDs []string `json:"ignore_project_ids"`
+		// QuietHours is a pointer to tell an omitted window (inherited) from an empty one (none).
+		QuietHours *string `json:"quiet_hours"`
+	} `json:"profiles"`
+}
+
+// LoadProfiles reads profiles from a JSON file. Profiles are matched in file order.
+// Omitted schedule, ignored projects and quiet hours are inherited from the default profile,
+// an empty quiet_hours disables quiet hours.
+func LoadProfiles(path string, def Profile) ([]Profile, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("read profiles file: %w", err)
+	}
+
+	var file profileFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode profiles file: %w", err)
+	}
+
+	names := map[string]bool{defaultProfileName: true}
+	res := make([]Profile, 0, len(file.Profiles))
+	for i, raw := range file.Profiles {
+		if raw.Name == "" {
+			return nil, fmt.Errorf("profile #%d: name is required", i+1)
+		}
+		if names[raw.Name] {
+			return nil, fmt.Errorf("profile %q: duplicate or reserved name", raw.Name)
+		}
+		names[raw.Name] = true
+
+		p := Profile{
+			Name:             raw.Name,
+			From:             raw.From,
+			To:               raw.To,
+			Schedule:         raw.Schedule,
+			FilterByTime:     raw.FilterByTime == nil || *raw.FilterByTime,
+			IgnoreProjectIDs: raw.IgnoreProjectIDs,
+			QuietHours:       def.QuietHours,
+		}
+		if p.Schedule == "" {
+			p.Schedule = def.Schedule
+		}
+		if p.IgnoreProjectIDs == nil {
+			p.IgnoreProjectIDs = def.IgnoreProjectIDs
+		}
+		for _, d := range raw.Weekdays {
+			wd, err := parseWeekday(d)
+			if err != nil {
+				return nil, fmt.Errorf("profile %q: %w", raw.Name, err)
+			}
+			p.Weekdays = append(p.Weekdays, wd)
+		}
+		for _, d := range []string{p.From, p.To} {
+			if _, err := time.Parse(time.DateOnly, d); d != "" && err != nil {
+				return nil, fmt.Errorf("profile %q: invalid date %q: %w", raw.Name, d, err)
+			}
+		}
+		switch {
+		case raw.QuietHours == nil:
+			// inherited from the default profile
+		case *raw.QuietHours == "":
+			p.QuietHours = nil
+		default:
+			if p.QuietHours, err = ParseQuietHours(*raw.QuietHours); err != nil {
+				return nil, fmt.Errorf("profile %q: %w", raw.Name, err)
+			}
+		}
+		res = append(res, p)
+	}
+
+	return res, nil
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) >= 3 { //nolint:mnd // short weekday name
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if name := strings.ToLower(d.String()); strings.HasPrefix(name, s) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
@@ -0,0 +1,62 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+)
+
+func TestConfig_ActiveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	err := os.WriteFile(path, []byte(`{
+		"profiles": [
+			{"name": "vacation", "from": "2026-01-05", "to": "2026-01-06", "schedule": "0 12 * * *", "filter_by_time": false},
+			{"name": "weekend", "weekdays": ["sat", "sunday"], "schedule": "0 12 * * *", "quiet_hours": "22:00-10:00"},
+			{"name": "night shift", "weekdays": ["fri"], "quiet_hours": ""}
+		]
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conf := &internal.Config{Schedule: "0 9-23 * * *", IgnoreProjectIDs: []string{"p1"}, QuietHours: &internal.QuietHours{From: 22 * time.Hour}}
+	conf.Profiles, err = internal.LoadProfiles(path, conf.DefaultProfile())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		date     time.Time
+		expected string
+	}{
+		{date: time.Date(2026, 1, 5, 12, 0, 0, 0, time.UTC), expected: "vacation"},    // Monday within range
+		{date: time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC), expected: "default"},     // Wednesday
+		{date: time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC), expected: "weekend"},    // Saturday
+		{date: time.Date(2026, 1, 11, 12, 0, 0, 0, time.UTC), expected: "weekend"},    // Sunday
+		{date: time.Date(2026, 1, 9, 12, 0, 0, 0, time.UTC), expected: "night shift"}, // Friday
+	}
+	for _, tt := range tests {
+		if got := conf.ActiveProfile(tt.date).Name; got != tt.expected {
+			t.Errorf("%s: expected profile %q, got %q", tt.date.Format(time.DateOnly), tt.expected, got)
+		}
+	}
+
+	weekend := conf.Profiles[1]
+	if !weekend.FilterByTime || len(weekend.IgnoreProjectIDs) != 1 {
+		t.Errorf("expected weekend profile to inherit defaults, got %+v", weekend)
+	}
+	if !weekend.QuietHours.Contains(time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)) ||
+		!weekend.QuietHours.Contains(time.Date(2026, 1, 10, 9, 59, 0, 0, time.UTC)) ||
+		weekend.QuietHours.Contains(time.Date(2026, 1, 10, 10, 0, 0, 0, time.UTC)) {
+		t.Error("unexpected quiet hours window")
+	}
+	if vacation := conf.Profiles[0]; vacation.QuietHours != conf.QuietHours {
+		t.Errorf("expected vacation profile to inherit quiet hours, got %+v", vacation.QuietHours)
+	}
+	if nightShift := conf.Profiles[2]; nightShift.QuietHours != nil {
+		t.Errorf("expected empty quiet_hours to disable quiet hours, got %+v", nightShift.QuietHours)
+	}
+}
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"slices"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+const (
+	progressWidth      = 640
+	progressPadding    = 20
+	progressRowHeight  = 30
+	progressBarHeight  = 18
+	progressSwatch     = 18
+	progressSectionGap = 16
+	// progressMaxProjects limits the project rows of the image and the caption.
+	progressMaxProjects = 10
+	// progressRemainingAlpha is the opacity of the remaining part of a bar.
+	progressRemainingAlpha = 0.35
+)
+
+//nolint:gochecknoglobals // color palette
+var (
+	progressBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	progressTrack      = color.RGBA{R: 0xee, G: 0xee, B: 0xee, A: 0xff}
+	progressOverall    = color.RGBA{R: 0x05, G: 0x8b, B: 0x3d, A: 0xff}
+	// priorityColors follow the Todoist priority flags.
+	priorityColors = map[Priority]color.RGBA{
+		P1: {R: 0xd1, G: 0x45, B: 0x3b, A: 0xff},
+		P2: {R: 0xeb, G: 0x89, B: 0x09, A: 0xff},
+		P3: {R: 0x24, G: 0x6f, B: 0xe0, A: 0xff},
+		P4: {R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+	}
+	projectColors = []color.RGBA{
+		{R: 0x4c, G: 0x72, B: 0xb0, A: 0xff},
+		{R: 0xdd, G: 0x84, B: 0x52, A: 0xff},
+		{R: 0x55, G: 0xa8, B: 0x68, A: 0xff},
+		{R: 0xc4, G: 0x4e, B: 0x52, A: 0xff},
+		{R: 0x81, G: 0x72, B: 0xb3, A: 0xff},
+		{R: 0x93, G: 0x78, B: 0x60, A: 0xff},
+		{R: 0xda, G: 0x8b, B: 0xc3, A: 0xff},
+		{R: 0x8c, G: 0x8c, B: 0x8c, A: 0xff},
+		{R: 0xcc, G: 0xb9, B: 0x74, A: 0xff},
+		{R: 0x64, G: 0xb5, B: 0xcd, A: 0xff},
+	}
+)
+
+// Progress counts today's done and remaining tasks.
+type Progress struct {
+	Done  int
+	Total int
+	// Priorities has a row per priority, from P1 to P4.
+	Priorities []ProgressRow
+	// Projects has a row per project with tasks, in Todoist project order.
+	Projects []ProgressRow
+}
+
+// ProgressRow counts the done and remaining tasks of a priority or project.
+type ProgressRow struct {
+	Label    string
+	Priority int
+	Done     int
+	Total    int
+
+	projectRank int
+}
+
+// NewProgress counts the tasks done today and the ones remaining. The catalog resolves project names.
+func NewProgress(done, remaining []todoist.Task, catalog *Catalog) Progress {
+	res := Progress{Done: len(done), Total: len(done) + len(remaining)}
+	for _, p := range []Priority{P1, P2, P3, P4} {
+		res.Priorities = append(res.Priorities, ProgressRow{Label: fmt.Sprintf("P%d", int(P1-p)+1), Priority: int(p)})
+	}
+
+	projects := make(map[string]int)
+	count := func(t todoist.Task, isDone bool) {
+		i, ok := projects[t.ProjectID]
+		if !ok {
+			i = len(res.Projects)
+			projects[t.ProjectID] = i
+			res.Projects = append(res.Projects, ProgressRow{
+				Label:       catalog.ProjectName(t.ProjectID),
+				projectRank: catalog.projectRank(t.ProjectID),
+			})
+		}
+		priority := &res.Priorities[int(P1)-min(max(t.Priority, int(P4)), int(P1))]
+		project := &res.Projects[i]
+		priority.Total++
+		project.Total++
+		if isDone {
+			priority.Done++
+			project.Done++
+		}
+	}
+	for _, t := range done {
+		count(t, true)
+	}
+	for _, t := range remaining {
+		count(t, false)
+	}
+
+	slices.SortStableFunc(res.Projects, func(a, b ProgressRow) int {
+		return cmp.Compare(a.projectRank, b.projectRank)
+	})
+
+	return res
+}
+
+// RenderProgressImage draws progress bars as a PNG: the overall progress, a bar per priority and
+// a bar per project. The done part of a bar is solid, the remaining part is faded. Bars within a
+// section are scaled to the largest total. The image has no text, the caption explains the rows.
+func RenderProgressImage(p Progress) ([]byte, error) {
+	projects := p.Projects[:min(len(p.Projects), progressMaxProjects)]
+	rows := 1 + len(p.Priorities) + len(projects)
+	height := 2*progressPadding + rows*progressRowHeight + 2*progressSectionGap
+
+	img := image.NewRGBA(image.Rect(0, 0, progressWidth, height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(progressBackground), image.Point{}, draw.Src)
+
+	y := progressPadding
+	drawProgressRow(img, y, progressOverall, p.Done, p.Total, p.Total)
+	y += progressRowHeight + progressSectionGap
+
+	maxTotal := 0
+	for _, r := range p.Priorities {
+		maxTotal = max(maxTotal, r.Total)
+	}
+	for _, r := range p.Priorities {
+		drawProgressRow(img, y, priorityColors[Priority(r.Priority)], r.Done, r.Total, maxTotal)
+		y += progressRowHeight
+	}
+	y += progressSectionGap
+
+	maxTotal = 0
+	for _, r := range projects {
+		maxTotal = max(maxTotal, r.Total)
+	}
+	for i, r := range projects {
+		drawProgressRow(img, y, projectColors[i%len(projectColors)], r.Done, r.Total, maxTotal)
+		y += progressRowHeight
+	}
+
+	buff := &bytes.Buffer{}
+	if err := png.Encode(buff, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+	return buff.Bytes(), nil
+}
+
+// drawProgressRow draws a color swatch and a bar of total tasks, done of them solid, scaled to maxTotal.
+func drawProgressRow(img draw.Image, y int, c color.RGBA, done, total, maxTotal int) {
+	fill := func(x0, x1 int, c color.Color) {
+		r := image.Rect(x0, y, x1, y+progressBarHeight)
+		draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+	}
+
+	fill(progressPadding, progressPadding+progressSwatch, c)
+
+	left := progressPadding + progressSwatch + progressPadding/2
+	width := progressWidth - progressPadding - left
+	fill(left, left+width, progressTrack)
+	if maxTotal == 0 || total == 0 {
+		return
+	}
+
+	barWidth := width * total / maxTotal
+	doneWidth := barWidth * done / total
+	fill(left, left+doneWidth, c)
+	fill(left+doneWidth, left+barWidth, fade(c, progressRemainingAlpha))
+}
+
+// fade blends c with white, keeping alpha of its intensity.
+func fade(c color.RGBA, alpha float64) color.RGBA {
+	blend := func(v uint8) uint8 {
+		return uint8(float64(v)*alpha + 0xff*(1-alpha)) //nolint:gosec // result is within 0..255
+	}
+	return color.RGBA{R: blend(c.R), G: blend(c.G), B: blend(c.B), A: 0xff}
+}
@@ -0,0 +1,106 @@
+package internal_test
+
+import (
+	"bytes"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestNewProgress(t *testing.T) {
+	catalog := internal.NewCatalog(
+		[]todoist.Project{{ID: "work", Name: "Work", ChildOrder: 1}, {ID: "home", Name: "Home", ChildOrder: 2}},
+		nil,
+	)
+	done := []todoist.Task{
+		{ID: "1", Content: "standup", Priority: 4, ProjectID: "home"},
+		{ID: "2", Content: "review", Priority: 3, ProjectID: "work"},
+	}
+	remaining := []todoist.Task{
+		{ID: "3", Content: "report", Priority: 4, ProjectID: "work"},
+		{ID: "4", Content: "laundry", Priority: 1, ProjectID: "home"},
+		{ID: "5", Content: "call", Priority: 1, ProjectID: "work"},
+	}
+
+	progress := internal.NewProgress(done, remaining, catalog)
+	if progress.Done != 2 || progress.Total != 5 {
+		t.Errorf("expected 2 of 5 done, got %d of %d", progress.Done, progress.Total)
+	}
+
+	priorities := []internal.ProgressRow{
+		{Label: "P1", Priority: 4, Done: 1, Total: 2},
+		{Label: "P2", Priority: 3, Done: 1, Total: 1},
+		{Label: "P3", Priority: 2},
+		{Label: "P4", Priority: 1, Total: 2},
+	}
+	for i, want := range priorities {
+		if got := progress.Priorities[i]; got.Label != want.Label || got.Priority != want.Priority || got.Done != want.Done || got.Total != want.Total {
+			t.Errorf("priority row %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	if len(progress.Projects) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", progress.Projects)
+	}
+	if work := progress.Projects[0]; work.Label != "Work" || work.Done != 1 || work.Total != 3 {
+		t.Errorf("expected Work first with 1 of 3 done, got %+v", work)
+	}
+	if home := progress.Projects[1]; home.Label != "Home" || home.Done != 1 || home.Total != 2 {
+		t.Errorf("expected Home with 1 of 2 done, got %+v", home)
+	}
+
+	data, err := internal.RenderProgressImage(progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected a valid PNG: %v", err)
+	}
+	if img.Bounds().Dx() != 640 || img.Bounds().Dy() < 7*30 {
+		t.Errorf("unexpected image size %v", img.Bounds())
+	}
+
+	msgs, err := internal.RenderSummaryMessage(progress, remaining[:1], internal.RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "📊 Done today: 2 of 5\n" +
+		"🔴 1/2 · 🟠 1/1 · 🔵 0/0 · ⚪ 0/2\n" +
+		"📁 Work: 1/3\n" +
+		"📁 Home: 1/2\n" +
+		"\n" +
+		"Still to do:\n" +
+		"- 🔴 report\n"
+	if len(msgs) != 1 || msgs[0] != want {
+		t.Errorf("expected caption %q, got %q", want, msgs)
+	}
+}
+
+func TestRenderSummaryMessage_ManyProjects(t *testing.T) {
+	var (
+		projects []todoist.Project
+		done     []todoist.Task
+	)
+	for p := range 12 {
+		id := fmt.Sprintf("p%d", p)
+		projects = append(projects, todoist.Project{ID: id, Name: "Project " + id, ChildOrder: p})
+		done = append(done, todoist.Task{ID: id, Content: "task " + id, Priority: 1, ProjectID: id})
+	}
+	progress := internal.NewProgress(done, nil, internal.NewCatalog(projects, nil))
+
+	msgs, err := internal.RenderSummaryMessage(progress, nil, internal.RenderOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 || strings.Count(msgs[0], "📁") != 11 {
+		t.Fatalf("expected 10 project rows and a remainder, got %q", msgs)
+	}
+	if !strings.Contains(msgs[0], "📁 Project p9: 1/1\n📁 +2 more\n") || strings.Contains(msgs[0], "p10") {
+		t.Errorf("expected the projects of the image and +2 more, got %q", msgs[0])
+	}
+}
@@ -0,0 +1,419 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+type GroupMode string
+
+const (
+	GroupNone GroupMode = "none"
+	// GroupByProject renders a header per project, in Todoist project order.
+	GroupByProject GroupMode = "project"
+	// GroupBySection renders a header per project and section.
+	GroupBySection GroupMode = "section"
+)
+
+var tasksTemplate = mustMessageTemplate("tasks", `{{- define "task" }}
+- {{ .ShownPriority | toCircle }} {{ if and .Show.Time .Time }}{{ esc .Time }} {{ end }}{{ title . }}
+{{- if .Show.Labels }}{{ range .Tags }} #{{ esc . }}{{ end }}{{ end }}
+{{- if and .Show.Project .Project }} · {{ esc .Project }}{{ end }}
+{{- with .Deadline }} ⏰ {{ esc . }}{{ end }}{{ with .Reminders }} 🔁 {{ . }}×{{ end }}
+{{- if and .Show.Description .Description }}
+  {{ esc .Description }}
+{{- end }}
+{{- end }}
+{{- define "groups" }}
+{{- range . }}
+
+📁 {{ esc .Title }} ({{ len .Tasks }})
+{{- range .Tasks }}{{ template "task" . }}{{ end }}
+{{- end }}
+{{- end }}
+{{- define "changes" }}
+{{- if .New }}{{ t "changes.new" (formatTime .Since "15:04") }}
+{{- if .NewGroups }}{{ template "groups" .NewGroups }}
+{{- else }}{{ range .New }}{{ template "task" . }}{{ end }}{{ end }}
+{{ if or .Pending .Completed }}
+{{ end }}
+{{- end }}
+{{- if .Pending }}{{ t "changes.pending" }}
+{{- if .PendingGroups }}{{ template "groups" .PendingGroups }}
+{{- else }}{{ range .Pending }}{{ template "task" . }}{{ end }}{{ end }}
+{{ if .Completed }}
+{{ end }}
+{{- end }}
+{{- if .Completed }}{{ t "changes.completed" }}
+{{- range .Completed }}
+- {{ .Priority | toCircle }} {{ esc .Content }}
+{{- end }}
+{{ end }}
+{{- end }}
+{{- if .Changes }}{{ template "changes" .Changes }}
+{{- else }}
+{{- if .Pending }}{{ t "tasks.pending" }}
+{{- range .Pending }}{{ template "task" . }}{{ end }}
+{{ if .Tasks }}
+{{ end }}
+{{- end }}
+{{- if .Tasks }}{{ t "tasks.header" }}
+{{- if .Groups }}{{ template "groups" .Groups }}
+{{- else }}
+{{- range .Tasks }}{{ template "task" . }}{{ end }}
+{{- end }}
+{{ end }}
+{{- end }}
+{{- if .Habits }}{{ if or .Pending .Tasks (and .Changes .Changes.Completed) }}
+{{ end }}{{ t "tasks.habits" }} {{ range $i, $t := .Habits }}{{ if $i }} · {{ end }}{{ title $t }}{{ end }}
+{{ end }}
+{{- if .Workload.Overbooked }}
+{{ t "tasks.overbooked" (formatDuration .Workload.Planned) (formatDuration .Workload.Available) (formatTime .Workload.EndOfDay "15:04") }}
+{{ end }}`)
+
+var agendaTemplate = mustMessageTemplate("agenda", `{{ esc .Title }}
+{{- range .Days}}
+
+📅 {{ formatDate .Date | esc }}
+{{- range .Tasks}}
+- {{.Priority | toCircle}} {{ title . }}
+{{- end}}
+{{- end}}
+`)
+
+// RenderOptions configures RenderTasksMessage.
+type RenderOptions struct {
+	// Nagged maps IDs of long-ignored tasks to their notification count. They are moved
+	// to a separate "Still pending" block on top of the message.
+	Nagged map[string]int
+	// Escalated are the IDs of tasks included because of a close deadline. They are shown as P1.
+	Escalated map[string]bool
+	// Workload adds a warning when today's estimated work does not fit into the time left.
+	Workload *Workload
+	// Habits moves recurring tasks into a compact single line at the end of the message.
+	Habits bool
+	// GroupBy renders tasks under project (and section) headers. Requires Catalog.
+	GroupBy GroupMode
+	Catalog *Catalog
+	// Format is the markup of the message. Zero value renders plain text.
+	Format Format
+	Links  TaskLinks
+	// Template replaces the built-in task message template.
+	Template *MessageTemplate
+	// Lang is the language of the message. Zero value renders English.
+	Lang Lang
+	// Fields are the optional details shown on task lines.
+	Fields TaskFields
+	// Changes renders the difference to the previous notification instead of the task list.
+	Changes *TaskChanges
+}
+
+// TaskData is a task as seen by message templates.
+type TaskData struct {
+	todoist.Task
+	// Project and Section are names resolved from Todoist. Empty unless a catalog was fetched.
+	Project string
+	Section string
+	// Deadline is the countdown to the task deadline, if it is close.
+	Deadline string
+	// Overdue is set for tasks whose due date or deadline has passed.
+	Overdue bool
+	// Reminders is the number of notifications of a long-ignored task.
+	Reminders int
+	// Escalated is set for tasks included because of a close deadline, see ShownPriority.
+	Escalated bool
+	// URL links the task in Todoist, if links are enabled.
+	URL string
+	// Time is the due time of a timed task, e.g. "14:30".
+	Time string
+	// Tags are the task labels, except time labels.
+	Tags []string
+	// Description is the first line of the task description.
+	Description string
+	// Show are the details enabled for the task line.
+	Show TaskFields
+}
+
+// ShownPriority is the priority the task is rendered with: P1 for escalated tasks, the Todoist
+// priority otherwise.
+func (t TaskData) ShownPriority() int {
+	if t.Escalated {
+		return int(P1)
+	}
+	return t.Priority
+}
+
+// GroupData is a project (or project and section) group of tasks.
+type GroupData struct {
+	Title string
+	Tasks []TaskData
+
+	projectRank  int
+	sectionOrder int
+}
+
+// ChangesData compares the tasks with the previous notification.
+type ChangesData struct {
+	// Since is when the previous notification was sent.
+	Since time.Time
+	// New are the tasks not in the previous notification.
+	New []TaskData
+	// Pending are the tasks of the previous notification still to do, long-ignored ones first.
+	Pending []TaskData
+	// Completed are the tasks of the previous notification completed since.
+	Completed []TaskData
+	// NewGroups and PendingGroups are New and Pending grouped like MessageData.Groups.
+	// Empty unless grouping is enabled.
+	NewGroups     []GroupData
+	PendingGroups []GroupData
+}
+
+// Counts summarizes the rendered tasks.
+type Counts struct {
+	Total   int
+	P1      int
+	P2      int
+	P3      int
+	P4      int
+	Pending int
+	Habits  int
+	Overdue int
+}
+
+// MessageData is the data model available to task message templates.
+// Every task appears in exactly one of Pending, Tasks and Habits.
+type MessageData struct {
+	Now time.Time
+	// Pending are long-ignored tasks, see RenderOptions.Nagged.
+	Pending []TaskData
+	// Tasks are the regular tasks, in sort order.
+	Tasks []TaskData
+	// Groups are Tasks grouped by project (and section). Empty unless grouping is enabled.
+	Groups []GroupData
+	// Habits are recurring tasks, when they are rendered in a separate line.
+	Habits []TaskData
+	// Overdue are all tasks (from any of the lists above) whose due date or deadline has passed.
+	Overdue  []TaskData
+	Counts   Counts
+	Workload *Workload
+	// Projects maps project IDs to names. Empty unless a catalog was fetched.
+	Projects map[string]string
+	// Changes splits Pending and Tasks by the previous notification. Nil unless RenderOptions.Changes is set.
+	Changes *ChangesData
+}
+
+// RenderTasksMessage renders tasks into one or more messages that fit into the Telegram limit.
+func RenderTasksMessage(tasks []todoist.Task, now time.Time, opts RenderOptions) ([]string, error) {
+	data := NewMessageData(tasks, now, opts)
+
+	tmpl := opts.Template
+	if tmpl == nil {
+		tmpl = tasksTemplate
+	}
+	msg, err := tmpl.execute(opts.format(), opts.lang(), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitMessage(msg, TelegramMessageLimit, opts.format()), nil
+}
+
+// NewMessageData builds the template data model from filtered and sorted tasks.
+func NewMessageData(tasks []todoist.Task, now time.Time, opts RenderOptions) MessageData {
+	data := MessageData{Now: now, Workload: opts.Workload, Projects: opts.Catalog.projectNames()}
+	today := now.Format(time.DateOnly)
+	for _, t := range tasks {
+		td := TaskData{
+			Task:      t,
+			Project:   opts.Catalog.ProjectName(t.ProjectID),
+			Section:   opts.Catalog.SectionName(t.SectionID),
+			Deadline:  deadlineCountdown(t, now, opts.lang()),
+			Overdue:   (dueDate(t, now.Location()) != "" && dueDate(t, now.Location()) < today) || timeToDeadline(t, now) <= 0,
+			Escalated: opts.Escalated[t.ID],
+			URL:       opts.Links.TaskURL(t.ID),
+
+			Time:        taskTime(t, now.Location()),
+			Tags:        taskTags(t),
+			Description: clip(firstLine(t.Description), opts.Fields.MaxDescription),
+			Show:        opts.Fields,
+		}
+		td.Content = clip(t.Content, opts.Fields.MaxContent)
+		if td.Overdue {
+			data.Overdue = append(data.Overdue, td)
+		}
+		data.Counts.add(t)
+
+		if count, ok := opts.Nagged[t.ID]; ok {
+			td.Reminders = count
+			data.Pending = append(data.Pending, td)
+			continue
+		}
+		if opts.Habits && isRecurring(t) {
+			data.Habits = append(data.Habits, td)
+			continue
+		}
+		data.Tasks = append(data.Tasks, td)
+	}
+	if opts.GroupBy == GroupByProject || opts.GroupBy == GroupBySection {
+		data.Groups = groupTasks(data.Tasks, opts.GroupBy, opts.Catalog, opts.lang())
+	}
+	if opts.Changes != nil {
+		data.Changes = newChangesData(data, opts)
+	}
+	data.Counts.Pending, data.Counts.Habits, data.Counts.Overdue = len(data.Pending), len(data.Habits), len(data.Overdue)
+
+	return data
+}
+
+func newChangesData(data MessageData, opts RenderOptions) *ChangesData {
+	res := &ChangesData{Since: opts.Changes.Since}
+	for _, td := range slices.Concat(data.Pending, data.Tasks) {
+		if opts.Changes.Previous[td.ID] {
+			res.Pending = append(res.Pending, td)
+		} else {
+			res.New = append(res.New, td)
+		}
+	}
+	for _, t := range opts.Changes.Completed {
+		res.Completed = append(res.Completed, TaskData{Task: t, URL: opts.Links.TaskURL(t.ID)})
+	}
+	if opts.GroupBy == GroupByProject || opts.GroupBy == GroupBySection {
+		res.NewGroups = groupTasks(res.New, opts.GroupBy, opts.Catalog, opts.lang())
+		res.PendingGroups = groupTasks(res.Pending, opts.GroupBy, opts.Catalog, opts.lang())
+	}
+	return res
+}
+
+func (c *Counts) add(t todoist.Task) {
+	c.Total++
+	switch Priority(t.Priority) {
+	case P1:
+		c.P1++
+	case P2:
+		c.P2++
+	case P3:
+		c.P3++
+	case P4:
+		c.P4++
+	}
+}
+
+// topPriority returns the highest priority among the counted tasks, P4 without tasks.
+func (c Counts) topPriority() Priority {
+	switch {
+	case c.P1 > 0:
+		return P1
+	case c.P2 > 0:
+		return P2
+	case c.P3 > 0:
+		return P3
+	default:
+		return P4
+	}
+}
+
+// groupTasks groups tasks by project (and section) in Todoist order. Tasks keep their order within a group.
+func groupTasks(tasks []TaskData, mode GroupMode, catalog *Catalog, lang Lang) []GroupData {
+	var res []GroupData
+	index := make(map[string]int)
+	for _, t := range tasks {
+		key := t.ProjectID
+		if mode == GroupBySection {
+			key += "/" + t.SectionID
+		}
+
+		i, ok := index[key]
+		if !ok {
+			group := GroupData{
+				Title:        catalog.ProjectName(t.ProjectID),
+				projectRank:  catalog.projectRank(t.ProjectID),
+				sectionOrder: -1,
+			}
+			if group.Title == "" {
+				group.Title = lang.T("tasks.other")
+			}
+			if mode == GroupBySection && t.SectionID != "" {
+				group.Title += " › " + catalog.SectionName(t.SectionID)
+				group.sectionOrder = catalog.sectionOrder(t.SectionID)
+			}
+			i = len(res)
+			index[key] = i
+			res = append(res, group)
+		}
+		res[i].Tasks = append(res[i].Tasks, t)
+	}
+
+	slices.SortStableFunc(res, func(a, b GroupData) int {
+		return cmp.Or(
+			cmp.Compare(a.projectRank, b.projectRank),
+			cmp.Compare(a.sectionOrder, b.sectionOrder),
+		)
+	})
+
+	return res
+}
+
+func (o RenderOptions) format() Format {
+	if o.Format == "" {
+		return FormatPlain
+	}
+	return o.Format
+}
+
+func (o RenderOptions) lang() Lang {
+	if o.Lang == "" {
+		return LangEnglish
+	}
+	return o.Lang
+}
+
+type agendaDay struct {
+	Date  time.Time
+	Tasks []TaskData
+}
+
+type agendaView struct {
+	Title string
+	Days  []agendaDay
+}
+
+// RenderAgendaMessage renders tasks grouped by due day. Tasks must be ordered by due date,
+// as returned by FilterAndSortTasksInRange. Only Format, Links and Lang of opts are used.
+func RenderAgendaMessage(title string, tasks []todoist.Task, loc *time.Location, opts RenderOptions) ([]string, error) {
+	view := agendaView{Title: title}
+	for _, t := range tasks {
+		date, err := time.ParseInLocation(time.DateOnly, dueDate(t, loc), loc)
+		if err != nil {
+			return nil, fmt.Errorf("parse due date of task %s: %w", t.ID, err)
+		}
+		if len(view.Days) == 0 || !view.Days[len(view.Days)-1].Date.Equal(date) {
+			view.Days = append(view.Days, agendaDay{Date: date})
+		}
+		last := &view.Days[len(view.Days)-1]
+		last.Tasks = append(last.Tasks, TaskData{Task: t, URL: opts.Links.TaskURL(t.ID)})
+	}
+
+	msg, err := agendaTemplate.execute(opts.format(), opts.lang(), view)
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitMessage(msg, TelegramMessageLimit, opts.format()), nil
+}
+
+func toCircle(priority int) string {
+	switch priority {
+	case 4:
+		return "🔴"
+	case 3:
+		return "🟠"
+	case 2:
+		return "🔵"
+	default:
+		return "⚪"
+	}
+}
@@ -0,0 +1,274 @@
+package internal_test
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestRenderTasksMessage_Grouped(t *testing.T) {
+	catalog := internal.NewCatalog(
+		[]todoist.Project{{ID: "work", Name: "Work", ChildOrder: 1}, {ID: "home", Name: "Home", ChildOrder: 2}},
+		[]todoist.Section{{ID: "s1", ProjectID: "work", Name: "Meetings", SectionOrder: 1}},
+	)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "laundry", Priority: 4, ProjectID: "home"},
+		{ID: "2", Content: "standup", Priority: 4, ProjectID: "work", SectionID: "s1"},
+		{ID: "3", Content: "review PR", Priority: 3, ProjectID: "work"},
+		{ID: "4", Content: "unknown", Priority: 1, ProjectID: "gone"},
+	}
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		mode     internal.GroupMode
+		expected string
+	}{
+		{
+			mode: internal.GroupByProject,
+			expected: "Uncompleted tasks for today:\n\n" +
+				"📁 Work (2)\n- 🔴 standup\n- 🟠 review PR\n\n" +
+				"📁 Home (1)\n- 🔴 laundry\n\n" +
+				"📁 Other (1)\n- ⚪ unknown\n",
+		},
+		{
+			mode: internal.GroupBySection,
+			expected: "Uncompleted tasks for today:\n\n" +
+				"📁 Work (1)\n- 🟠 review PR\n\n" +
+				"📁 Work › Meetings (1)\n- 🔴 standup\n\n" +
+				"📁 Home (1)\n- 🔴 laundry\n\n" +
+				"📁 Other (1)\n- ⚪ unknown\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			msg, err := singleMessage(internal.RenderTasksMessage(tasks, now, internal.RenderOptions{GroupBy: tt.mode, Catalog: catalog}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, msg)
+			}
+		})
+	}
+}
+
+func TestRenderTasksMessage_Fields(t *testing.T) {
+	catalog := internal.NewCatalog([]todoist.Project{{ID: "work", Name: "Work"}}, nil)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "Prepare the quarterly report", Priority: 4, ProjectID: "work",
+			Due: &todoist.TaskDue{Date: "2026-01-11T14:30:00"}, Labels: []string{"3pm", "deep work"},
+			Description: "\nSlides first, then numbers\nsecond line"},
+		{ID: "2", Content: "call mom", Priority: 1, Due: &todoist.TaskDue{Date: "2026-01-11"}},
+	}
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		fields   internal.TaskFields
+		expected string
+	}{
+		{
+			name:     "none",
+			expected: "Uncompleted tasks for today:\n- 🔴 Prepare the quarterly report\n- ⚪ call mom\n",
+		},
+		{
+			name:   "all",
+			fields: internal.TaskFields{Time: true, Labels: true, Description: true, Project: true},
+			expected: "Uncompleted tasks for today:\n" +
+				"- 🔴 14:30 Prepare the quarterly report #deep_work · Work\n  Slides first, then numbers\n" +
+				"- ⚪ call mom\n",
+		},
+		{
+			name:   "truncated",
+			fields: internal.TaskFields{Description: true, MaxContent: 12, MaxDescription: 10},
+			expected: "Uncompleted tasks for today:\n" +
+				"- 🔴 Prepare the…\n  Slides fi…\n" +
+				"- ⚪ call mom\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := singleMessage(internal.RenderTasksMessage(tasks, now, internal.RenderOptions{Fields: tt.fields, Catalog: catalog}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg != tt.expected {
+				t.Errorf("expected message %q, got %q", tt.expected, msg)
+			}
+		})
+	}
+}
+
+func TestParseTaskFields(t *testing.T) {
+	fields, err := internal.ParseTaskFields("time, Labels,project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fields.Time || !fields.Labels || !fields.Project || fields.Description {
+		t.Errorf("unexpected fields %+v", fields)
+	}
+	if _, err := internal.ParseTaskFields("time,emoji"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+func TestRenderTasksMessage_Split(t *testing.T) {
+	tasks := make([]todoist.Task, 0, 300)
+	for i := range 300 {
+		tasks = append(tasks, todoist.Task{
+			ID:       strconv.Itoa(i),
+			Content:  fmt.Sprintf("task #%03d with a reasonably long description (v1.%d)", i, i),
+			Priority: 4,
+		})
+	}
+
+	for _, format := range []internal.Format{internal.FormatPlain, internal.FormatHTML, internal.FormatMarkdown} {
+		t.Run(string(format), func(t *testing.T) {
+			msgs, err := internal.RenderTasksMessage(tasks, time.Now(), internal.RenderOptions{Format: format, Links: internal.LinksWeb})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(msgs) < 2 {
+				t.Fatalf("expected several chunks, got %d", len(msgs))
+			}
+
+			seen := 0
+			for i, msg := range msgs {
+				if n := len(utf16.Encode([]rune(msg))); n > internal.TelegramMessageLimit {
+					t.Errorf("chunk %d is %d UTF-16 units long", i+1, n)
+				}
+				suffix := format.Escape(fmt.Sprintf("(%d/%d)", i+1, len(msgs))) + "\n"
+				if !strings.HasSuffix(msg, suffix) {
+					t.Errorf("chunk %d does not end with %q", i+1, suffix)
+				}
+				for _, line := range strings.Split(strings.TrimSuffix(msg, suffix), "\n") {
+					if strings.Contains(line, "reasonably long") {
+						seen++
+						if format == internal.FormatHTML && !strings.HasSuffix(line, "</b></a>") {
+							t.Errorf("task line cut in chunk %d: %q", i+1, line)
+						}
+					}
+				}
+			}
+			if seen != len(tasks) {
+				t.Errorf("expected %d task lines across chunks, got %d", len(tasks), seen)
+			}
+		})
+	}
+}
+
+func TestSplitMessage_Continuation(t *testing.T) {
+	var sb strings.Builder
+	for i := range 20 {
+		fmt.Fprintf(&sb, "- task %02d\n  description %02d\n", i, i)
+	}
+
+	msgs := internal.SplitMessage(sb.String(), 64, internal.FormatPlain)
+	if len(msgs) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(msgs))
+	}
+	for i, msg := range msgs {
+		lines := strings.Split(strings.TrimSuffix(msg, "\n"), "\n")
+		// the last line is the chunk number
+		lines = lines[:len(lines)-1]
+		if len(lines)%2 != 0 || !strings.HasPrefix(lines[0], "- task") {
+			t.Errorf("chunk %d splits a task from its description: %q", i+1, msg)
+		}
+	}
+}
+
+func TestSplitMessage_LongLine(t *testing.T) {
+	msgs := internal.SplitMessage(strings.Repeat("🔴", 100), 64, internal.FormatPlain)
+	total := 0
+	for _, msg := range msgs {
+		if n := len(utf16.Encode([]rune(msg))); n > 64 {
+			t.Errorf("chunk is %d UTF-16 units long", n)
+		}
+		total += strings.Count(msg, "🔴")
+	}
+	if total != 100 {
+		t.Errorf("expected all runes to be kept, got %d", total)
+	}
+}
+
+func TestSplitMessage_LongLineMarkup(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		format internal.Format
+		tokens []string
+	}{
+		{
+			name:   "html entities",
+			line:   strings.Repeat("a&amp;b ", 40),
+			format: internal.FormatHTML,
+			tokens: []string{"a&amp;b"},
+		},
+		{
+			name:   "html links",
+			line:   strings.Repeat(`<a href="https://t.co/1">task</a> `, 10),
+			format: internal.FormatHTML,
+			tokens: []string{`<a href="https://t.co/1">task</a>`},
+		},
+		{
+			name:   "markdown escapes",
+			line:   strings.Repeat(`a\.b\-c `, 40),
+			format: internal.FormatMarkdown,
+			tokens: []string{`a\.b\-c`},
+		},
+		{
+			name:   "markdown bold",
+			line:   strings.Repeat("*bold text* ", 30),
+			format: internal.FormatMarkdown,
+			tokens: []string{"*bold text*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := internal.SplitMessage(tt.line, 64, tt.format)
+			if len(msgs) < 2 {
+				t.Fatalf("expected the line to be split, got %d chunk(s)", len(msgs))
+			}
+			var joined strings.Builder
+			for _, msg := range msgs {
+				if n := len(utf16.Encode([]rune(msg))); n > 64 {
+					t.Errorf("chunk is %d UTF-16 units long", n)
+				}
+				// drop the chunk number line
+				body := strings.TrimRight(msg, "\n")
+				body = body[:strings.LastIndex(body, "\n")]
+				for _, token := range tt.tokens {
+					rest := strings.ReplaceAll(body, token, "")
+					if strings.TrimSpace(rest) != "" {
+						t.Errorf("chunk %q cuts a markup token, left %q", body, rest)
+					}
+				}
+				joined.WriteString(body)
+			}
+			if joined.String() != tt.line {
+				t.Errorf("expected all text to be kept, got %q", joined.String())
+			}
+		})
+	}
+}
+
+// singleMessage unwraps a render result that is expected to fit into one message.
+func singleMessage(msgs []string, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	if len(msgs) != 1 {
+		return "", errors.New("expected a single message, got " + strconv.Itoa(len(msgs)))
+	}
+	return msgs[0], nil
+}
@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// slackMaxBlocks is the maximum number of blocks in a Slack message.
+	slackMaxBlocks = 50
+	// slackMaxSectionText is the maximum length of the text of a section block.
+	slackMaxSectionText = 3000
+	// slackMaxHeaderText is the maximum length of the text of a header block.
+	slackMaxHeaderText = 150
+)
+
+// SlackChannel posts digests to a Slack incoming webhook using Block Kit.
+type SlackChannel struct {
+	webhookURL string
+	client     HTTPClient
+}
+
+type slackMessage struct {
+	// Text is the fallback shown in notifications.
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func NewSlackChannel(webhookURL string, client HTTPClient) *SlackChannel {
+	return &SlackChannel{webhookURL: webhookURL, client: client}
+}
+
+func (c *SlackChannel) Name() string {
+	return "slack"
+}
+
+func (c *SlackChannel) Send(ctx context.Context, digest Digest) error {
+	resp, err := postJSON(ctx, c.client, c.webhookURL, newSlackMessage(digest), nil)
+	if err != nil {
+		return fmt.Errorf("post slack message: %w", err)
+	}
+	if err := resp.checkStatus(); err != nil {
+		return fmt.Errorf("post slack message: %w", err)
+	}
+	return nil
+}
+
+// newSlackMessage renders the digest as Block Kit blocks: a header, a section per project
+// (split when too long) and context blocks for habits and overbooking.
+func newSlackMessage(d Digest) slackMessage {
+	title := d.Lang.T("tasks.header")
+	res := slackMessage{
+		Text:   fmt.Sprintf("%s %d", title, d.Counts.Total),
+		Blocks: []slackBlock{{Type: "header", Text: &slackText{Type: "plain_text", Text: clip(title, slackMaxHeaderText)}}},
+	}
+
+	if len(d.Pending) > 0 {
+		res.Blocks = append(res.Blocks, slackSections(d.Lang.T("tasks.pending"), d.Pending)...)
+	}
+	for _, g := range d.Groups {
+		res.Blocks = append(res.Blocks, slackSections(g.Title, g.Tasks)...)
+	}
+	if len(d.Habits) > 0 {
+		names := make([]string, len(d.Habits))
+		for i, h := range d.Habits {
+			names[i] = slackLink(h)
+		}
+		res.Blocks = append(res.Blocks, slackContext(d.Lang.T("tasks.habits")+" "+strings.Join(names, " · ")))
+	}
+	if d.Workload.Overbooked() {
+		w := d.Workload
+		res.Blocks = append(res.Blocks, slackContext(slackEscape(d.Lang.T("tasks.overbooked",
+			d.Lang.FormatDuration(w.Planned), d.Lang.FormatDuration(w.Available), d.Lang.FormatTime(w.EndOfDay, "15:04")))))
+	}
+
+	if len(res.Blocks) > slackMaxBlocks {
+		omitted := len(res.Blocks) - slackMaxBlocks + 1
+		res.Blocks = append(res.Blocks[:slackMaxBlocks-1], slackContext(fmt.Sprintf("… +%d", omitted)))
+	}
+
+	return res
+}
+
+// slackSections renders a titled list of tasks as one or more section blocks within the text limit.
+func slackSections(title string, tasks []TaskData) []slackBlock {
+	var (
+		res []slackBlock
+		cur = "*" + slackEscape(title) + "*"
+	)
+	for _, t := range tasks {
+		line := taskLine(t, slackLink(t))
+		if utf8.RuneCountInString(cur)+1+utf8.RuneCountInString(line) > slackMaxSectionText {
+			res = append(res, slackSection(cur))
+			cur = ""
+		}
+		if cur != "" {
+			cur += "\n"
+		}
+		cur += clip(line, slackMaxSectionText)
+	}
+	return append(res, slackSection(cur))
+}
+
+func slackSection(text string) slackBlock {
+	return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}}
+}
+
+func slackContext(text string) slackBlock {
+	return slackBlock{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: text}}}
+}
+
+// slackLink renders the task content linked to the task, bold for P1.
+func slackLink(t TaskData) string {
+	res := slackEscape(t.Content)
+	if Priority(t.Priority) == P1 {
+		res = "*" + res + "*"
+	}
+	if t.URL == "" {
+		return res
+	}
+	return "<" + t.URL + "|" + res + ">"
+}
+
+// slackEscape escapes the control characters of Slack mrkdwn.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,98 @@
+package internal_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func testDigest() internal.Digest {
+	catalog := internal.NewCatalog(
+		[]todoist.Project{{ID: "work", Name: "Work", ChildOrder: 1}, {ID: "home", Name: "Home", ChildOrder: 2}},
+		nil,
+	)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "Fix <prod> & deploy", Priority: 4, ProjectID: "work"},
+		{ID: "2", Content: "laundry", Priority: 3, ProjectID: "home"},
+		{ID: "3", Content: "review", Priority: 2, ProjectID: "work"},
+		{ID: "4", Content: "stretch", Priority: 1, ProjectID: "home", Due: &todoist.TaskDue{Date: "2026-01-11", IsRecurring: true}},
+	}
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+	return internal.NewDigest(tasks, now, internal.RenderOptions{Catalog: catalog, Habits: true}, "run-1")
+}
+
+// recordingServer records request bodies and replies with the given status.
+func recordingServer(t *testing.T, status int) (*httptest.Server, *[]*http.Request, *[][]byte) {
+	t.Helper()
+	var (
+		requests []*http.Request
+		bodies   [][]byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests, &bodies
+}
+
+func TestSlackChannel(t *testing.T) {
+	srv, _, bodies := recordingServer(t, http.StatusOK)
+
+	if err := internal.NewSlackChannel(srv.URL, srv.Client()).Send(context.Background(), testDigest()); err != nil {
+		t.Fatal(err)
+	}
+
+	var msg struct {
+		Text   string `json:"text"`
+		Blocks []struct {
+			Type string `json:"type"`
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+			Elements []struct {
+				Text string `json:"text"`
+			} `json:"elements"`
+		} `json:"blocks"`
+	}
+	if err := json.Unmarshal((*bodies)[0], &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(msg.Blocks) != 4 {
+		t.Fatalf("expected header, 2 project sections and habits, got %+v", msg.Blocks)
+	}
+	if msg.Blocks[0].Type != "header" || msg.Blocks[0].Text.Text != "Uncompleted tasks for today:" {
+		t.Errorf("unexpected header %+v", msg.Blocks[0])
+	}
+	work := "*Work*\n" +
+		"🔴 <https://app.todoist.com/app/task/1|*Fix &lt;prod&gt; &amp; deploy*>\n" +
+		"🔵 <https://app.todoist.com/app/task/3|review>"
+	if msg.Blocks[1].Text.Text != work {
+		t.Errorf("expected work section %q, got %q", work, msg.Blocks[1].Text.Text)
+	}
+	if !strings.HasPrefix(msg.Blocks[2].Text.Text, "*Home*\n🟠 ") {
+		t.Errorf("expected home section, got %q", msg.Blocks[2].Text.Text)
+	}
+	if msg.Blocks[3].Type != "context" || !strings.HasPrefix(msg.Blocks[3].Elements[0].Text, "Habits: ") {
+		t.Errorf("expected habits context, got %+v", msg.Blocks[3])
+	}
+}
+
+func TestSlackChannel_Error(t *testing.T) {
+	srv, _, _ := recordingServer(t, http.StatusForbidden)
+
+	if err := internal.NewSlackChannel(srv.URL, srv.Client()).Send(context.Background(), testDigest()); err == nil {
+		t.Error("expected error for rejected webhook")
+	}
+}
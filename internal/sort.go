@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+type SortStrategy string
+
+const (
+	// SortByPriority orders by priority, then by due time (timed tasks first).
+	SortByPriority SortStrategy = "priority"
+	// SortByTodoistOrder keeps the manual order of tasks within Todoist (child_order).
+	SortByTodoistOrder SortStrategy = "todoist"
+	// SortByProject orders by Todoist project order, then by child_order.
+	SortByProject SortStrategy = "project"
+	// SortByRevealTime orders by the hour a task becomes visible (time labels or priority).
+	SortByRevealTime SortStrategy = "reveal"
+	// SortAlphabetically orders by task content.
+	SortAlphabetically SortStrategy = "alpha"
+)
+
+var sortStrategies = []SortStrategy{SortByPriority, SortByTodoistOrder, SortByProject, SortByRevealTime, SortAlphabetically} //nolint:gochecknoglobals // constant list
+
+func ParseSortStrategy(s string) (SortStrategy, error) {
+	if s == "" {
+		return SortByPriority, nil
+	}
+	res := SortStrategy(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(sortStrategies, res) {
+		return "", fmt.Errorf("unknown sort strategy %q, expected one of %s", s, joinStrategies())
+	}
+	return res, nil
+}
+
+func joinStrategies() string {
+	res := make([]string, len(sortStrategies))
+	for i, s := range sortStrategies {
+		res[i] = string(s)
+	}
+	return strings.Join(res, "|")
+}
+
+// SortTasks sorts tasks in place with the given strategy. Sorting is stable, so tasks that
+// compare equal keep the order returned by Todoist. An unknown strategy falls back to SortByPriority.
+// catalog is only used by SortByProject and may be nil.
+func SortTasks(tasks []todoist.Task, strategy SortStrategy, catalog *Catalog, loc *time.Location) {
+	sortTasks(tasks, strategy, catalog, loc, nil)
+}
+
+// sortTasks sorts like SortTasks, with the tasks whose IDs are in escalated ordered as P1.
+func sortTasks(tasks []todoist.Task, strategy SortStrategy, catalog *Catalog, loc *time.Location, escalated map[string]bool) {
+	priority := func(t todoist.Task) int {
+		if escalated[t.ID] {
+			return int(P1)
+		}
+		return t.Priority
+	}
+	var compare func(a, b todoist.Task) int
+
+	switch strategy {
+	case SortByTodoistOrder:
+		compare = func(a, b todoist.Task) int {
+			return cmp.Compare(a.ChildOrder, b.ChildOrder)
+		}
+	case SortByProject:
+		compare = func(a, b todoist.Task) int {
+			return cmp.Or(
+				cmp.Compare(catalog.projectRank(a.ProjectID), catalog.projectRank(b.ProjectID)),
+				cmp.Compare(a.ChildOrder, b.ChildOrder),
+			)
+		}
+	case SortByRevealTime:
+		compare = func(a, b todoist.Task) int {
+			return cmp.Or(
+				cmp.Compare(revealHour(a), revealHour(b)),
+				cmp.Compare(priority(b), priority(a)),
+			)
+		}
+	case SortAlphabetically:
+		compare = func(a, b todoist.Task) int {
+			return strings.Compare(strings.ToLower(a.Content), strings.ToLower(b.Content))
+		}
+	case SortByPriority:
+		fallthrough
+	default:
+		compare = func(a, b todoist.Task) int {
+			return cmp.Or(
+				cmp.Compare(priority(b), priority(a)),
+				compareDueTime(a, b, loc),
+			)
+		}
+	}
+
+	slices.SortStableFunc(tasks, compare)
+}
+
+// compareDueTime orders timed tasks by their time, before tasks that only have a date.
+func compareDueTime(a, b todoist.Task, loc *time.Location) int {
+	at, aok := dueTime(a, loc)
+	bt, bok := dueTime(b, loc)
+	switch {
+	case aok && bok:
+		return at.Compare(bt)
+	case aok:
+		return -1
+	case bok:
+		return 1
+	default:
+		return 0
+	}
+}
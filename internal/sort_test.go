@@ -0,0 +1,80 @@
+package internal_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestSortTasks(t *testing.T) {
+	catalog := internal.NewCatalog([]todoist.Project{
+		{ID: "inbox", Name: "Inbox", ChildOrder: 0},
+		{ID: "work", Name: "Work", ChildOrder: 2},
+		{ID: "home", Name: "Home", ChildOrder: 1},
+		{ID: "work-sub", Name: "Work sub", ParentID: "work", ChildOrder: 0},
+	}, nil)
+
+	tasks := []todoist.Task{
+		{ID: "1", Content: "banana", Priority: 3, ProjectID: "work-sub", ChildOrder: 4, Due: &todoist.TaskDue{Date: "2026-01-11"}},
+		{ID: "2", Content: "Apple", Priority: 4, ProjectID: "work", ChildOrder: 3, Labels: []string{"6pm"}, Due: &todoist.TaskDue{Date: "2026-01-11"}},
+		{ID: "3", Content: "cherry", Priority: 3, ProjectID: "home", ChildOrder: 1, Due: &todoist.TaskDue{Date: "2026-01-11T09:30:00"}},
+		{ID: "4", Content: "date", Priority: 1, ProjectID: "inbox", ChildOrder: 2, Due: &todoist.TaskDue{Date: "2026-01-11"}},
+		{ID: "5", Content: "elderberry", Priority: 3, ProjectID: "home", ChildOrder: 0, Due: &todoist.TaskDue{Date: "2026-01-11T08:00:00Z"}},
+	}
+
+	tests := []struct {
+		strategy internal.SortStrategy
+		expected []string
+	}{
+		{strategy: internal.SortByPriority, expected: []string{"2", "5", "3", "1", "4"}},
+		{strategy: internal.SortByTodoistOrder, expected: []string{"5", "3", "4", "2", "1"}},
+		{strategy: internal.SortByProject, expected: []string{"4", "5", "3", "2", "1"}},
+		{strategy: internal.SortByRevealTime, expected: []string{"1", "3", "5", "2", "4"}},
+		{strategy: internal.SortAlphabetically, expected: []string{"2", "1", "3", "4", "5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			sorted := append([]todoist.Task(nil), tasks...)
+			internal.SortTasks(sorted, tt.strategy, catalog, time.UTC)
+
+			for i, id := range tt.expected {
+				if sorted[i].ID != id {
+					t.Errorf("expected task[%d] to be %q, got %q", i, id, sorted[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSortTasks_Stable(t *testing.T) {
+	tasks := []todoist.Task{
+		{ID: "1", Content: "same", Priority: 4},
+		{ID: "2", Content: "same", Priority: 4},
+		{ID: "3", Content: "same", Priority: 4},
+	}
+
+	for _, strategy := range []internal.SortStrategy{internal.SortByPriority, internal.SortByRevealTime, internal.SortAlphabetically} {
+		sorted := append([]todoist.Task(nil), tasks...)
+		internal.SortTasks(sorted, strategy, nil, time.UTC)
+		for i, task := range sorted {
+			if task.ID != tasks[i].ID {
+				t.Errorf("%s: expected stable order, got %q at %d", strategy, task.ID, i)
+			}
+		}
+	}
+}
+
+func TestParseSortStrategy(t *testing.T) {
+	if s, err := internal.ParseSortStrategy(""); err != nil || s != internal.SortByPriority {
+		t.Errorf("expected default strategy, got %q, %v", s, err)
+	}
+	if s, err := internal.ParseSortStrategy(" Alpha "); err != nil || s != internal.SortAlphabetically {
+		t.Errorf("expected alpha strategy, got %q, %v", s, err)
+	}
+	if _, err := internal.ParseSortStrategy("random"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
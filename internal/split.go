@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// TelegramMessageLimit is the maximum length of a Telegram text message, in UTF-16 code units.
+const TelegramMessageLimit = 4096
+
+// chunkNumberReserve is the room kept in every chunk for the "(1/3)" suffix.
+const chunkNumberReserve = 16
+
+// SplitMessage splits a rendered message into chunks of at most limit UTF-16 code units.
+// Chunks are split at line boundaries, which are task boundaries in rendered messages, so no
+// markup entity is ever cut. Indented lines, e.g. task descriptions, stay with the line above.
+// Only a single line longer than the limit is cut mid-line, preferably at a space outside of any
+// markup element.
+// When there is more than one chunk, each one ends with its number, e.g. "(1/3)".
+// The length is measured on the markup, which is never shorter than the text Telegram counts.
+func SplitMessage(msg string, limit int, f Format) []string {
+	if utf16Len(msg) <= limit {
+		return []string{msg}
+	}
+
+	budget := limit - chunkNumberReserve
+	var (
+		res     []string
+		cur     strings.Builder
+		curSize int
+	)
+	flush := func() {
+		if curSize > 0 {
+			res = append(res, cur.String())
+			cur.Reset()
+			curSize = 0
+		}
+	}
+
+	for _, line := range splitBlocks(msg) {
+		size := utf16Len(line)
+		if curSize+size > budget {
+			flush()
+		}
+		for size > budget {
+			head, tail := cutUTF16(line, budget, f)
+			res = append(res, head)
+			line, size = tail, utf16Len(tail)
+		}
+		cur.WriteString(line)
+		curSize += size
+	}
+	flush()
+
+	for i, chunk := range res {
+		res[i] = strings.TrimRight(chunk, "\n") + "\n" + f.Escape(fmt.Sprintf("(%d/%d)", i+1, len(res))) + "\n"
+	}
+
+	return res
+}
+
+// splitBlocks splits msg after newlines, keeping indented continuation lines with the line above.
+func splitBlocks(msg string) []string {
+	var res []string
+	for _, line := range strings.SplitAfter(msg, "\n") {
+		if len(res) > 0 && strings.HasPrefix(line, "  ") {
+			res[len(res)-1] += line
+			continue
+		}
+		res = append(res, line)
+	}
+	return res
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// Cut positions of cutUTF16, from the most to the least preferred.
+const (
+	cutSpace = iota
+	cutElement
+	cutToken
+	cutRune
+	cutKinds
+)
+
+// cutUTF16 cuts s after at most n UTF-16 code units. It prefers the last space outside of markup
+// elements, then any position outside of them. Only when there is none it cuts inside an element,
+// still never inside an HTML tag or entity or a MarkdownV2 escape, and as a last resort at a rune.
+func cutUTF16(s string, n int, f Format) (string, string) {
+	var (
+		m     markupScanner
+		cuts  [cutKinds]int
+		size  int
+		space bool
+	)
+	m.format = f
+	for i, r := range s {
+		if i > 0 {
+			cuts[cutRune] = i
+			if !m.inToken() {
+				cuts[cutToken] = i
+				if m.depth() == 0 {
+					cuts[cutElement] = i
+					if space {
+						cuts[cutSpace] = i
+					}
+				}
+			}
+		}
+		if size+utf16.RuneLen(r) > n {
+			for _, cut := range cuts {
+				if cut > 0 {
+					return s[:cut], s[cut:]
+				}
+			}
+			return s[:i], s[i:]
+		}
+		size += utf16.RuneLen(r)
+		space = r == ' '
+		m.next(r)
+	}
+	return s, ""
+}
+
+// markupScanner tracks where in the markup of a rendered message a position is.
+type markupScanner struct {
+	format Format
+
+	// HTML
+	tag, closing, entity bool
+	tagSize              int
+	elements             int
+
+	// MarkdownV2
+	escaped  bool
+	emphasis map[rune]bool
+	links    int
+}
+
+// inToken reports whether the position is inside an HTML tag or entity or a MarkdownV2 escape.
+func (m *markupScanner) inToken() bool {
+	return m.tag || m.entity || m.escaped
+}
+
+// depth returns the number of open markup elements, e.g. bold text or a link.
+func (m *markupScanner) depth() int {
+	res := m.elements + m.links
+	for _, open := range m.emphasis {
+		if open {
+			res++
+		}
+	}
+	return res
+}
+
+func (m *markupScanner) next(r rune) {
+	switch m.format {
+	case FormatHTML:
+		m.nextHTML(r)
+	case FormatMarkdown:
+		m.nextMarkdown(r)
+	case FormatPlain:
+		// no markup
+	}
+}
+
+func (m *markupScanner) nextHTML(r rune) {
+	switch {
+	case m.tag:
+		m.tagSize++
+		switch {
+		case r == '/' && m.tagSize == 1:
+			m.closing = true
+		case r == '>':
+			m.tag = false
+			if m.closing {
+				m.elements = max(m.elements-1, 0)
+			} else {
+				m.elements++
+			}
+		}
+	case m.entity:
+		m.entity = r != ';'
+	case r == '<':
+		m.tag, m.closing, m.tagSize = true, false, 0
+	case r == '&':
+		m.entity = true
+	}
+}
+
+func (m *markupScanner) nextMarkdown(r rune) {
+	switch {
+	case m.escaped:
+		m.escaped = false
+	case r == '\\':
+		m.escaped = true
+	case r == '*' || r == '_' || r == '~':
+		if m.emphasis == nil {
+			m.emphasis = make(map[rune]bool)
+		}
+		m.emphasis[r] = !m.emphasis[r]
+	case r == '[':
+		m.links++
+	case r == ')' && m.links > 0:
+		m.links--
+	}
+}
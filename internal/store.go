@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// State is the bot state persisted across restarts.
+type State struct {
+	// Vacation is an ad-hoc away period set with /vacation.
+	Vacation *DateRange `json:"vacation,omitempty"`
+	// Notified counts scheduled notifications per task ID.
+	Notified map[string]NotifiedTask `json:"notified,omitempty"`
+	// Sent are the tasks of the last scheduled notification per chat.
+	Sent map[int64]SentTasks `json:"sent,omitempty"`
+	// Live are the pinned messages with today's tasks per chat, see Config.LiveMessage.
+	Live map[int64]LiveMessage `json:"live,omitempty"`
+	// Languages are per chat languages set with /language.
+	Languages map[int64]Lang `json:"languages,omitempty"`
+	// Matrix are the last digests per Matrix room, see MatrixConfig.Edit.
+	Matrix map[string]MatrixMessage `json:"matrix,omitempty"`
+}
+
+// FileStore keeps State in memory and persists every update to a JSON file.
+// An empty path keeps the state in memory only.
+type FileStore struct {
+	path string
+
+	mx    sync.Mutex
+	state State
+}
+
+func OpenFileStore(path string) (*FileStore, error) {
+	res := &FileStore{path: path}
+	if path == "" {
+		return res, nil
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from trusted configuration
+	if errors.Is(err, fs.ErrNotExist) {
+		return res, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &res.state); err != nil {
+		return nil, fmt.Errorf("decode state file: %w", err)
+	}
+
+	return res, nil
+}
+
+// View calls fn with the current state. fn must not retain or modify it.
+func (s *FileStore) View(fn func(state *State)) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	fn(&s.state)
+}
+
+// Update calls fn with a copy of the current state and persists the changes it made.
+// If fn returns an error or the state can not be saved, the state is left unchanged.
+func (s *FileStore) Update(fn func(state *State) error) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	state, err := s.state.clone()
+	if err != nil {
+		return err
+	}
+	if err := fn(&state); err != nil {
+		return err
+	}
+	if err := s.save(state); err != nil {
+		return err
+	}
+
+	s.state = state
+	return nil
+}
+
+// clone returns a deep copy of the state. The state is plain JSON data, so it is copied through JSON.
+func (st *State) clone() (State, error) {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return State{}, fmt.Errorf("encode state: %w", err)
+	}
+	var res State
+	if err := json.Unmarshal(data, &res); err != nil {
+		return State{}, fmt.Errorf("decode state: %w", err)
+	}
+	return res, nil
+}
+
+// save writes the state to a temporary file and renames it, so a crash never leaves a partial file.
+func (s *FileStore) save(state State) error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write state file: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace state file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package internal_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+)
+
+func TestFileStore_Update(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := internal.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Update(func(state *internal.State) error {
+		state.Notified = map[string]internal.NotifiedTask{"1": {Count: 1}}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := internal.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reopened.View(func(state *internal.State) {
+		if state.Notified["1"].Count != 1 {
+			t.Errorf("expected the update to be persisted, got %+v", state.Notified)
+		}
+	})
+}
+
+func TestFileStore_UpdateFailure(t *testing.T) {
+	// the temporary file can not be written over a directory
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.Mkdir(path+".tmp", 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		err  error
+	}{
+		{name: "update error", path: "", err: errors.New("boom")},
+		{name: "save error", path: path},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, err := internal.OpenFileStore(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = store.Update(func(state *internal.State) error {
+				state.Notified = map[string]internal.NotifiedTask{"1": {Count: 1}}
+				state.Notified["2"] = internal.NotifiedTask{Count: 2}
+				return tt.err
+			})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			store.View(func(state *internal.State) {
+				if len(state.Notified) != 0 {
+					t.Errorf("expected the state to be unchanged, got %+v", state.Notified)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+// TelegramCaptionLimit is the maximum length of a Telegram photo caption, in UTF-16 code units.
+const TelegramCaptionLimit = 1024
+
+var summaryTemplate = mustMessageTemplate("summary", `{{ t "summary.header" .Progress.Done .Progress.Total }}
+{{ range $i, $r := .Progress.Priorities }}{{ if $i }} · {{ end }}{{ toCircle $r.Priority }} {{ $r.Done }}/{{ $r.Total }}{{ end }}
+{{- range .Projects }}
+📁 {{ with .Label }}{{ esc . }}{{ else }}{{ t "tasks.other" }}{{ end }}: {{ .Done }}/{{ .Total }}
+{{- end }}
+{{- with .MoreProjects }}
+📁 {{ t "summary.more" . }}
+{{- end }}
+{{- if .Tasks }}
+
+{{ t "summary.remaining" }}
+{{- range .Tasks }}
+- {{ .Priority | toCircle }} {{ title . }}
+{{- end }}
+{{- end }}
+`)
+
+// SummaryData is the data model of the evening summary caption.
+type SummaryData struct {
+	Progress Progress
+	// Projects are the project rows of the image, MoreProjects counts the ones left out.
+	Projects     []ProgressRow
+	MoreProjects int
+	// Tasks are the remaining tasks.
+	Tasks []TaskData
+}
+
+// RenderSummaryMessage renders the progress counts and the remaining tasks into chunks that fit
+// into a photo caption. Only Format, Links and Lang of opts are used.
+func RenderSummaryMessage(progress Progress, remaining []todoist.Task, opts RenderOptions) ([]string, error) {
+	projects := progress.Projects[:min(len(progress.Projects), progressMaxProjects)]
+	data := SummaryData{Progress: progress, Projects: projects, MoreProjects: len(progress.Projects) - len(projects)}
+	for _, t := range remaining {
+		data.Tasks = append(data.Tasks, TaskData{Task: t, URL: opts.Links.TaskURL(t.ID)})
+	}
+
+	msg, err := summaryTemplate.execute(opts.format(), opts.lang(), data)
+	if err != nil {
+		return nil, err
+	}
+
+	return SplitMessage(msg, TelegramCaptionLimit, opts.format()), nil
+}
+
+// SendSummary sends the evening summary: a progress image of today's done and remaining tasks by
+// priority and project, with the remaining tasks as caption. Nothing is sent on a day without tasks.
+func (b *Bot) SendSummary(chatID int64) error {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	now := b.clock.Now()
+	if b.isDayOff(now) {
+		b.log.DebugContext(ctx, "day off, skipping summary")
+		return nil
+	}
+
+	openTasks, err := b.todoistClient.GetTasksLimit200(ctx, false)
+	if err != nil {
+		return fmt.Errorf("get tasks: %w", err)
+	}
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	completedTasks, err := b.todoistClient.GetCompletedTasks(ctx, startOfDay, now)
+	if err != nil {
+		return fmt.Errorf("get completed tasks: %w", err)
+	}
+
+	opts, err := b.filterOptions(ctx, false, b.conf.ActiveProfile(now), b.conf.SortStrategy)
+	if err != nil {
+		return err
+	}
+	opts.FilterByTime = false
+	if opts.Catalog == nil {
+		if opts.Catalog, err = b.fetchCatalog(ctx); err != nil {
+			return err
+		}
+	}
+
+	remaining := FilterAndSortTasks(openTasks, now, opts)
+	var done []todoist.Task
+	for _, t := range completedTasks {
+		if countsAsDone(t, now, opts) {
+			done = append(done, t)
+		}
+	}
+
+	progress := NewProgress(done, remaining, opts.Catalog)
+	if progress.Total == 0 {
+		b.log.DebugContext(ctx, "no tasks today, skipping summary")
+		return nil
+	}
+
+	img, err := RenderProgressImage(progress)
+	if err != nil {
+		return fmt.Errorf("render progress image: %w", err)
+	}
+	renderOpts := RenderOptions{Format: b.conf.MessageFormat, Links: b.conf.TaskLinks, Lang: b.lang(chatID)}
+	msgs, err := RenderSummaryMessage(progress, remaining, renderOpts)
+	if err != nil {
+		return fmt.Errorf("render summary message: %w", err)
+	}
+
+	photo := Message{Text: msgs[0], Format: b.conf.MessageFormat, Photo: img}
+	if _, err := b.messenger.Send(ctx, chatID, photo); err != nil {
+		return fmt.Errorf("send summary photo: %w", err)
+	}
+	if _, err := b.sendMessages(ctx, chatID, msgs[1:], b.conf.MessageFormat, true); err != nil {
+		return err
+	}
+
+	b.log.DebugContext(ctx, "summary sent successfully")
+	return nil
+}
+
+// countsAsDone reports whether a task completed today is one of today's tasks, filtered like the
+// remaining ones. Completing a recurring task moves it to its next occurrence, so only the rules
+// apply to it.
+func countsAsDone(t todoist.Task, now time.Time, opts FilterOptions) bool {
+	if isRecurring(t) {
+		return opts.allows(t)
+	}
+	return decide(t, now, opts).Included()
+}
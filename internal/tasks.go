@@ -1,10 +1,9 @@
 package internal
 
 import (
-	"bytes"
-	"fmt"
-	"sort"
-	"text/template"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
@@ -12,6 +11,12 @@ import (
 
 type Priority int
 
+const (
+	day = 24 * time.Hour
+	// deadlineCountdownHorizon is how far ahead deadlines are shown next to a task.
+	deadlineCountdownHorizon = 7 * day
+)
+
 const (
 	P1 Priority = 4
 	P2 Priority = 3
@@ -19,110 +24,191 @@ const (
 	P4 Priority = 1
 )
 
-var tasksTemplate = template.Must(template.New("tasks").
-	Funcs(template.FuncMap{
-		"toCircle": toCircle,
-	}).
-	Parse(`Uncompleted tasks for today:
-{{- range .}}
-- {{.Priority | toCircle}} {{ .Content }}
-{{- end}}
-`))
-
-func FilterAndSortTasks(tasks []todoist.Task, now time.Time, filterByTime bool, ignoreProjects []string) []todoist.Task {
+func FilterAndSortTasks(tasks []todoist.Task, now time.Time, opts FilterOptions) []todoist.Task {
 	if len(tasks) == 0 {
 		return nil
 	}
 
-	ignoreProjectsMap := make(map[string]bool)
-	for _, project := range ignoreProjects {
-		ignoreProjectsMap[project] = true
-	}
-	date := now.Format("2006-01-02")
 	res := make([]todoist.Task, 0, len(tasks))
+	escalated := make(map[string]bool)
 	for _, t := range tasks {
-		if t.Due == nil || t.Due.Date != date {
+		switch decide(t, now, opts) {
+		case FilterIncluded:
+		case FilterEscalated:
+			escalated[t.ID] = true
+		default:
 			continue
 		}
+		res = append(res, t)
+	}
+
+	// escalated tasks keep their Todoist priority but sort as P1
+	sortTasks(res, opts.Sort, opts.Catalog, now.Location(), escalated)
+
+	return res
+}
+
+// ExplainFilter returns the FilterAndSortTasks decision about every task, in the given order.
+func ExplainFilter(tasks []todoist.Task, now time.Time, opts FilterOptions) []FilterDecision {
+	res := make([]FilterDecision, len(tasks))
+	for i, t := range tasks {
+		res[i] = FilterDecision{TaskID: t.ID, Reason: decide(t, now, opts)}
+	}
+	return res
+}
+
+// decide returns whether a task is shown in the notification at now, and why.
+func decide(t todoist.Task, now time.Time, opts FilterOptions) FilterReason {
+	escalate := opts.DeadlineWindow > 0 && timeToDeadline(t, now) <= opts.DeadlineWindow
+	// timed tasks count on their day, not only all-day tasks with a bare date
+	if dueDate(t, now.Location()) != now.Format(time.DateOnly) && !escalate {
+		return FilterNotDue
+	}
+
+	if escalate {
+		// a close deadline makes the task P1, also for MinPriority
+		t.Priority = int(P1)
+	}
+	if reason := opts.rejects(t); reason != "" {
+		return reason
+	}
+
+	if isRecurring(t) && opts.hidesRecurring(now) {
+		return FilterRecurringHidden
+	}
+
+	if escalate {
+		return FilterEscalated
+	}
+	if opts.FilterByTime && now.Hour() < revealHour(t) {
+		return FilterNotRevealed
+	}
+	return FilterIncluded
+}
+
+// FilterAndSortTasksInRange returns tasks due on any day between from and to (inclusive),
+// ordered by due date and then by opts.Sort. Time based filtering and deadline escalation
+// only make sense for today and are not applied.
+func FilterAndSortTasksInRange(tasks []todoist.Task, from, to time.Time, opts FilterOptions) []todoist.Task {
+	if len(tasks) == 0 {
+		return nil
+	}
 
-		if ignoreProjectsMap[t.ProjectID] {
+	fromDate, toDate := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	res := make([]todoist.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if date := dueDate(t, from.Location()); date < fromDate || date > toDate {
 			continue
 		}
 
-		if !filterByTime {
-			res = append(res, t)
+		if isRecurring(t) && opts.ExcludeRecurring {
 			continue
 		}
 
-		labels := timeLabels(t)
-		hasTimeLabel := len(labels) > 0
-
-		if hasTimeLabel {
-			switch {
-			case labels["12pm"] && now.Hour() < 12:
-				continue
-			case labels["3pm"] && now.Hour() < 15:
-				continue
-			case labels["6pm"] && now.Hour() < 18:
-				continue
-			case labels["9pm"] && now.Hour() < 21:
-				continue
-			default:
-				res = append(res, t)
-			}
-		} else {
-			switch Priority(t.Priority) {
-			case P1:
-				res = append(res, t)
-			case P2:
-				if now.Hour() >= 15 { //nolint:mnd // 3pm
-					res = append(res, t)
-				}
-			case P3:
-				if now.Hour() >= 18 { //nolint:mnd // 6pm
-					res = append(res, t)
-				}
-			case P4:
-				if now.Hour() >= 21 { //nolint:mnd // 9pm
-					res = append(res, t)
-				}
-			default:
-				res = append(res, t)
-			}
+		if !opts.allows(t) {
+			continue
 		}
-	}
 
-	sort.Slice(res, func(i, j int) bool {
-		if res[i].Priority == res[j].Priority {
-			return res[i].ProjectID < res[j].ProjectID
-		}
+		res = append(res, t)
+	}
 
-		return res[i].Priority > res[j].Priority
+	SortTasks(res, opts.Sort, opts.Catalog, from.Location())
+	slices.SortStableFunc(res, func(a, b todoist.Task) int {
+		return strings.Compare(dueDate(a, from.Location()), dueDate(b, from.Location()))
 	})
 
 	return res
 }
 
-func RenderTasksMessage(tasks []todoist.Task) (string, error) {
-	buff := &bytes.Buffer{}
-	if err := tasksTemplate.Execute(buff, tasks); err != nil {
-		return "", fmt.Errorf("execute template: %w", err)
+// revealHour returns the hour of day from which a task is shown in scheduled notifications.
+// Time labels take precedence over priority; with several time labels the latest one wins.
+func revealHour(task todoist.Task) int {
+	labels := timeLabels(task)
+	if len(labels) > 0 {
+		switch {
+		case labels["9pm"]:
+			return 21 //nolint:mnd // 9pm
+		case labels["6pm"]:
+			return 18 //nolint:mnd // 6pm
+		case labels["3pm"]:
+			return 15 //nolint:mnd // 3pm
+		default:
+			return 12 //nolint:mnd // 12pm
+		}
 	}
 
-	return buff.String(), nil
+	switch Priority(task.Priority) {
+	case P2:
+		return 15 //nolint:mnd // 3pm
+	case P3:
+		return 18 //nolint:mnd // 6pm
+	case P4:
+		return 21 //nolint:mnd // 9pm
+	case P1:
+		return 0
+	default:
+		return 0
+	}
 }
 
-func toCircle(priority int) string {
-	switch priority {
-	case 4:
-		return "🔴"
-	case 3:
-		return "🟠"
-	case 2:
-		return "🔵"
+// timeToDeadline returns the time left until the end of the task's deadline day (negative if passed).
+// Tasks without a deadline are infinitely far from it.
+func timeToDeadline(task todoist.Task, now time.Time) time.Duration {
+	if task.Deadline == nil {
+		return math.MaxInt64
+	}
+	deadline, err := time.ParseInLocation(time.DateOnly, task.Deadline.Date, now.Location())
+	if err != nil {
+		return math.MaxInt64
+	}
+	return deadline.AddDate(0, 0, 1).Sub(now)
+}
+
+// deadlineCountdown renders the time left until the task deadline, e.g. "5h left", "1d 3h left" or "overdue".
+// Tasks without a deadline, or with one further than a week away, render as an empty string.
+func deadlineCountdown(task todoist.Task, now time.Time, lang Lang) string {
+	left := timeToDeadline(task, now)
+	switch {
+	case left <= 0:
+		return lang.T("deadline.overdue")
+	case left > deadlineCountdownHorizon:
+		return ""
+	case left < day:
+		return lang.T("deadline.hours", int(left.Hours()))
 	default:
-		return "⚪"
+		days := left / day
+		return lang.T("deadline.days", int(days), int((left - days*day).Hours()))
+	}
+}
+
+func isRecurring(task todoist.Task) bool {
+	return task.Due != nil && task.Due.IsRecurring
+}
+
+// dueDate returns the date part (YYYY-MM-DD) of the task due, or an empty string if the task has no due.
+func dueDate(task todoist.Task, loc *time.Location) string {
+	// a UTC due time can fall on another day in loc
+	if t, ok := dueTime(task, loc); ok {
+		return t.Format(time.DateOnly)
+	}
+	if task.Due == nil || len(task.Due.Date) < len(time.DateOnly) {
+		return ""
+	}
+	return task.Due.Date[:len(time.DateOnly)]
+}
+
+// dueTime returns the due time of a timed task. Floating due times are interpreted in loc.
+func dueTime(task todoist.Task, loc *time.Location) (time.Time, bool) {
+	if task.Due == nil || len(task.Due.Date) <= len(time.DateOnly) {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339Nano, task.Due.Date); err == nil {
+		return t.In(loc), true
+	}
+	if t, err := time.ParseInLocation("2006-01-02T15:04:05", task.Due.Date, loc); err == nil {
+		return t, true
 	}
+	return time.Time{}, false
 }
 
 func timeLabels(task todoist.Task) map[string]bool {
@@ -1,6 +1,7 @@
 package internal_test
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -150,7 +151,7 @@ func TestFilterAndSortTasks_PriorityFiltering(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			now := time.Date(2026, 1, 11, tt.hour, 0, 0, 0, time.UTC)
-			result := internal.FilterAndSortTasks(tt.tasks, now, true, tt.excludeProjectIDs)
+			result := internal.FilterAndSortTasks(tt.tasks, now, internal.FilterOptions{FilterByTime: true, IgnoreProjectIDs: tt.excludeProjectIDs})
 
 			if len(result) != len(tt.expected) {
 				t.Errorf("expected %d tasks, got %d", len(tt.expected), len(result))
@@ -165,3 +166,285 @@ func TestFilterAndSortTasks_PriorityFiltering(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterAndSortTasks_RuleFiltering(t *testing.T) {
+	date := "2026-01-11"
+	catalog := internal.NewCatalog(
+		[]todoist.Project{{ID: "p1", Name: "Work"}, {ID: "p2", Name: "Someday Maybe"}},
+		[]todoist.Section{{ID: "s1", ProjectID: "p1", Name: "Backlog"}, {ID: "s2", ProjectID: "p1", Name: "Doing"}},
+	)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "waiting task", Priority: 4, ProjectID: "p1", Labels: []string{"waiting"}, Due: &todoist.TaskDue{Date: date}},
+		{ID: "2", Content: "backlog task", Priority: 4, ProjectID: "p1", SectionID: "s1", Due: &todoist.TaskDue{Date: date}},
+		{ID: "3", Content: "doing task", Priority: 4, ProjectID: "p1", SectionID: "s2", Labels: []string{"focus"}, Due: &todoist.TaskDue{Date: date}},
+		{ID: "4", Content: "someday task", Priority: 4, ProjectID: "p2", Due: &todoist.TaskDue{Date: date}},
+	}
+
+	tests := []struct {
+		name     string
+		opts     internal.FilterOptions
+		expected []string
+	}{
+		{
+			name:     "no rules",
+			opts:     internal.FilterOptions{},
+			expected: []string{"waiting task", "backlog task", "doing task", "someday task"},
+		},
+		{
+			name:     "exclude label with @ prefix",
+			opts:     internal.FilterOptions{Labels: internal.Rule{Exclude: []string{"@waiting"}}},
+			expected: []string{"backlog task", "doing task", "someday task"},
+		},
+		{
+			name:     "include label",
+			opts:     internal.FilterOptions{Labels: internal.Rule{Include: []string{"foc*"}}},
+			expected: []string{"doing task"},
+		},
+		{
+			name:     "exclude section",
+			opts:     internal.FilterOptions{Sections: internal.Rule{Exclude: []string{"backlog"}}, Catalog: catalog},
+			expected: []string{"waiting task", "doing task", "someday task"},
+		},
+		{
+			name:     "exclude project glob",
+			opts:     internal.FilterOptions{Projects: internal.Rule{Exclude: []string{"Someday*"}}, Catalog: catalog},
+			expected: []string{"waiting task", "backlog task", "doing task"},
+		},
+		{
+			name: "include project, exclude wins",
+			opts: internal.FilterOptions{
+				Projects: internal.Rule{Include: []string{"Work"}},
+				Sections: internal.Rule{Exclude: []string{"Backlog"}},
+				Labels:   internal.Rule{Exclude: []string{"waiting"}},
+				Catalog:  catalog,
+			},
+			expected: []string{"doing task"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+			result := internal.FilterAndSortTasks(tasks, now, tt.opts)
+
+			if len(result) != len(tt.expected) {
+				t.Fatalf("expected %d tasks, got %d", len(tt.expected), len(result))
+			}
+			got := make(map[string]bool, len(result))
+			for _, r := range result {
+				got[r.Content] = true
+			}
+			for _, e := range tt.expected {
+				if !got[e] {
+					t.Errorf("expected task %q in result", e)
+				}
+			}
+		})
+	}
+}
+
+func TestFilterAndSortTasks_DeadlineEscalation(t *testing.T) {
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "P4 due today", Priority: 1, Due: &todoist.TaskDue{Date: "2026-01-11"}},
+		{ID: "2", Content: "deadline tomorrow", Priority: 1, Deadline: &todoist.TaskDeadline{Date: "2026-01-12"}},
+		{ID: "3", Content: "deadline next week", Priority: 1, Deadline: &todoist.TaskDeadline{Date: "2026-01-18"}},
+		{ID: "4", Content: "deadline passed", Priority: 2, Due: &todoist.TaskDue{Date: "2026-01-20"}, Deadline: &todoist.TaskDeadline{Date: "2026-01-10"}},
+		{ID: "5", Content: "P2 due today", Priority: 3, Labels: []string{"6pm"}, Due: &todoist.TaskDue{Date: "2026-01-11"}, Deadline: &todoist.TaskDeadline{Date: "2026-01-11"}},
+	}
+
+	result := internal.FilterAndSortTasks(tasks, now, internal.FilterOptions{FilterByTime: true, DeadlineWindow: 48 * time.Hour})
+
+	expected := []string{"deadline tomorrow", "deadline passed", "P2 due today"}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d tasks, got %d", len(expected), len(result))
+	}
+	for i, e := range expected {
+		if result[i].Content != e {
+			t.Errorf("expected task[%d] to be %q, got %q", i, e, result[i].Content)
+		}
+	}
+	if result[0].Priority != 1 || result[1].Priority != 2 {
+		t.Errorf("expected escalated tasks to keep their Todoist priority, got %d and %d", result[0].Priority, result[1].Priority)
+	}
+
+	escalated := make(map[string]bool)
+	for _, d := range internal.ExplainFilter(tasks, now, internal.FilterOptions{FilterByTime: true, DeadlineWindow: 48 * time.Hour}) {
+		escalated[d.TaskID] = d.Reason == internal.FilterEscalated
+	}
+	msg, err := singleMessage(internal.RenderTasksMessage(result, now, internal.RenderOptions{Escalated: escalated}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"🔴 deadline tomorrow ⏰ 1d 14h left", "🔴 deadline passed ⏰ overdue", "🔴 P2 due today ⏰ 14h left"} {
+		if !strings.Contains(msg, line) {
+			t.Errorf("expected message to contain %q, got:\n%s", line, msg)
+		}
+	}
+}
+
+func TestFilterAndSortTasksInRange(t *testing.T) {
+	from := time.Date(2026, 1, 12, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 2)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "today", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-11"}},
+		{ID: "2", Content: "day after P4", Priority: 1, Due: &todoist.TaskDue{Date: "2026-01-13"}},
+		{ID: "3", Content: "tomorrow P4", Priority: 1, Labels: []string{"9pm"}, Due: &todoist.TaskDue{Date: "2026-01-12"}},
+		{ID: "4", Content: "day after P1", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-13T10:00:00"}},
+		{ID: "5", Content: "out of range", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-15"}},
+		{ID: "6", Content: "ignored", Priority: 4, ProjectID: "x", Due: &todoist.TaskDue{Date: "2026-01-14"}},
+	}
+
+	result := internal.FilterAndSortTasksInRange(tasks, from, to, internal.FilterOptions{IgnoreProjectIDs: []string{"x"}})
+
+	expected := []string{"tomorrow P4", "day after P1", "day after P4"}
+	if len(result) != len(expected) {
+		t.Fatalf("expected %d tasks, got %d", len(expected), len(result))
+	}
+	for i, e := range expected {
+		if result[i].Content != e {
+			t.Errorf("expected task[%d] to be %q, got %q", i, e, result[i].Content)
+		}
+	}
+
+	msg, err := singleMessage(internal.RenderAgendaMessage("Week:", result, time.UTC, internal.RenderOptions{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedMsg := "Week:\n\n📅 Mon, Jan 12\n- ⚪ tomorrow P4\n\n📅 Tue, Jan 13\n- 🔴 day after P1\n- ⚪ day after P4\n"
+	if msg != expectedMsg {
+		t.Errorf("expected message %q, got %q", expectedMsg, msg)
+	}
+}
+
+func TestFilterAndSortTasks_Recurring(t *testing.T) {
+	date := "2026-01-11"
+	tasks := []todoist.Task{
+		{ID: "1", Content: "work", Priority: 4, Due: &todoist.TaskDue{Date: date}},
+		{ID: "2", Content: "meditate", Priority: 4, Due: &todoist.TaskDue{Date: date, IsRecurring: true}},
+		{ID: "3", Content: "read", Priority: 4, Due: &todoist.TaskDue{Date: date, IsRecurring: true}},
+	}
+	morning := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+	evening := time.Date(2026, 1, 11, 19, 30, 0, 0, time.UTC)
+
+	if result := internal.FilterAndSortTasks(tasks, morning, internal.FilterOptions{ExcludeRecurring: true}); len(result) != 1 {
+		t.Errorf("expected recurring tasks to be excluded, got %d tasks", len(result))
+	}
+
+	hideUntil := internal.FilterOptions{FilterByTime: true, RecurringFrom: 19 * time.Hour}
+	if result := internal.FilterAndSortTasks(tasks, morning, hideUntil); len(result) != 1 {
+		t.Errorf("expected recurring tasks to be hidden in the morning, got %d tasks", len(result))
+	}
+	result := internal.FilterAndSortTasks(tasks, evening, hideUntil)
+	if len(result) != 3 {
+		t.Fatalf("expected recurring tasks to be shown in the evening, got %d tasks", len(result))
+	}
+
+	msg, err := singleMessage(internal.RenderTasksMessage(result, evening, internal.RenderOptions{Habits: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Uncompleted tasks for today:\n- 🔴 work\n\nHabits: meditate · read\n"
+	if msg != expected {
+		t.Errorf("expected message %q, got %q", expected, msg)
+	}
+}
+
+func TestExplainFilter(t *testing.T) {
+	date := "2026-01-11"
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		task        todoist.Task
+		minPriority internal.Priority
+		expected    internal.FilterReason
+	}{
+		{
+			name:     "due today",
+			task:     todoist.Task{ID: "1", Priority: 4, Due: &todoist.TaskDue{Date: date}},
+			expected: internal.FilterIncluded,
+		},
+		{
+			name:     "due tomorrow",
+			task:     todoist.Task{ID: "2", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-12"}},
+			expected: internal.FilterNotDue,
+		},
+		{
+			name:     "deadline close",
+			task:     todoist.Task{ID: "3", Priority: 1, Deadline: &todoist.TaskDeadline{Date: "2026-01-12"}},
+			expected: internal.FilterEscalated,
+		},
+		{
+			name:        "deadline close on a downgraded day",
+			task:        todoist.Task{ID: "8", Priority: 1, Deadline: &todoist.TaskDeadline{Date: "2026-01-12"}},
+			minPriority: internal.P1,
+			expected:    internal.FilterEscalated,
+		},
+		{
+			name:        "below minimum priority",
+			task:        todoist.Task{ID: "9", Priority: 3, Due: &todoist.TaskDue{Date: date}},
+			minPriority: internal.P1,
+			expected:    internal.FilterMinPriority,
+		},
+		{
+			name:     "ignored project",
+			task:     todoist.Task{ID: "4", Priority: 4, ProjectID: "ignored", Due: &todoist.TaskDue{Date: date}},
+			expected: internal.FilterIgnoredProject,
+		},
+		{
+			name:     "excluded label",
+			task:     todoist.Task{ID: "5", Priority: 4, Labels: []string{"waiting"}, Due: &todoist.TaskDue{Date: date}},
+			expected: internal.FilterLabels,
+		},
+		{
+			name:     "time label not reached",
+			task:     todoist.Task{ID: "6", Priority: 4, Labels: []string{"3pm"}, Due: &todoist.TaskDue{Date: date}},
+			expected: internal.FilterNotRevealed,
+		},
+		{
+			name:     "recurring excluded",
+			task:     todoist.Task{ID: "7", Priority: 4, Due: &todoist.TaskDue{Date: date, IsRecurring: true}},
+			expected: internal.FilterRecurringHidden,
+		},
+	}
+
+	opts := internal.FilterOptions{
+		FilterByTime:     true,
+		IgnoreProjectIDs: []string{"ignored"},
+		DeadlineWindow:   48 * time.Hour,
+		ExcludeRecurring: true,
+		Labels:           internal.Rule{Exclude: []string{"waiting"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := opts
+			opts.MinPriority = tt.minPriority
+			decisions := internal.ExplainFilter([]todoist.Task{tt.task}, now, opts)
+			if len(decisions) != 1 || decisions[0].TaskID != tt.task.ID || decisions[0].Reason != tt.expected {
+				t.Fatalf("expected %s, got %+v", tt.expected, decisions)
+			}
+			included := len(internal.FilterAndSortTasks([]todoist.Task{tt.task}, now, opts)) == 1
+			if included != tt.expected.Included() {
+				t.Errorf("expected FilterAndSortTasks to agree with %s", tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilterAndSortTasks_TimedTasks(t *testing.T) {
+	kyiv := time.FixedZone("EET", 2*60*60)
+	now := time.Date(2026, 1, 11, 10, 0, 0, 0, kyiv)
+	tasks := []todoist.Task{
+		// 01:30 on Jan 11 in Kyiv
+		{ID: "1", Content: "early", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-10T23:30:00Z"}},
+		// 00:30 on Jan 12 in Kyiv
+		{ID: "2", Content: "tomorrow", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-11T22:30:00Z"}},
+		// floating due time, 15:00 wherever the user is
+		{ID: "3", Content: "floating", Priority: 4, Due: &todoist.TaskDue{Date: "2026-01-11T15:00:00"}},
+	}
+
+	got := internal.FilterAndSortTasks(tasks, now, internal.FilterOptions{})
+	if len(got) != 2 || got[0].ID != "1" || got[1].ID != "3" {
+		t.Errorf("expected the timed tasks due today in local time, got %+v", got)
+	}
+}
@@ -0,0 +1,93 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// TelegramMessenger is the Messenger of a Telegram bot.
+type TelegramMessenger struct {
+	bot *tele.Bot
+}
+
+// NewTelegramBot creates a long polling Telegram bot.
+func NewTelegramBot(token string) (*tele.Bot, error) {
+	b, err := tele.NewBot(tele.Settings{
+		Token:  token,
+		Poller: &tele.LongPoller{},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create bot: %w", err)
+	}
+	return b, nil
+}
+
+func NewTelegramMessenger(bot *tele.Bot) *TelegramMessenger {
+	return &TelegramMessenger{bot: bot}
+}
+
+func (m *TelegramMessenger) Send(_ context.Context, chatID int64, msg Message) (int, error) {
+	var what any = msg.Text
+	if msg.Photo != nil {
+		what = &tele.Photo{File: tele.FromReader(bytes.NewReader(msg.Photo)), Caption: msg.Text}
+	}
+
+	sent, err := m.bot.Send(&tele.Chat{ID: chatID}, what, sendOptions(msg)...)
+	if err != nil {
+		return 0, fmt.Errorf("send telegram message: %w", err)
+	}
+	return sent.ID, nil
+}
+
+func (m *TelegramMessenger) Edit(_ context.Context, chatID int64, messageID int, msg Message) error {
+	_, err := m.bot.Edit(storedMessage(chatID, messageID), msg.Text, sendOptions(msg)...)
+	switch {
+	case err == nil, errors.Is(err, tele.ErrMessageNotModified), errors.Is(err, tele.ErrSameMessageContent):
+		return nil
+	case errors.Is(err, tele.ErrCantEditMessage):
+		return fmt.Errorf("edit telegram message: %w: %w", ErrMessageNotFound, err)
+	default:
+		return fmt.Errorf("edit telegram message: %w", err)
+	}
+}
+
+func (m *TelegramMessenger) Delete(_ context.Context, chatID int64, messageID int) error {
+	if err := m.bot.Delete(storedMessage(chatID, messageID)); err != nil {
+		if errors.Is(err, tele.ErrNotFoundToDelete) {
+			return fmt.Errorf("delete telegram message: %w: %w", ErrMessageNotFound, err)
+		}
+		return fmt.Errorf("delete telegram message: %w", err)
+	}
+	return nil
+}
+
+func (m *TelegramMessenger) Pin(_ context.Context, chatID int64, messageID int) error {
+	if err := m.bot.Pin(storedMessage(chatID, messageID), tele.Silent); err != nil {
+		return fmt.Errorf("pin telegram message: %w", err)
+	}
+	return nil
+}
+
+func (m *TelegramMessenger) Unpin(_ context.Context, chatID int64, messageID int) error {
+	if err := m.bot.Unpin(&tele.Chat{ID: chatID}, messageID); err != nil {
+		return fmt.Errorf("unpin telegram message: %w", err)
+	}
+	return nil
+}
+
+func sendOptions(msg Message) []any {
+	opts := []any{msg.Format.ParseMode()}
+	if msg.Silent {
+		opts = append(opts, tele.Silent)
+	}
+	return opts
+}
+
+func storedMessage(chatID int64, id int) tele.StoredMessage {
+	return tele.StoredMessage{MessageID: strconv.Itoa(id), ChatID: chatID}
+}
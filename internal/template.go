@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+// MessageTemplate is a text/template parsed once per message Format. Literal text of the
+// template is escaped for each format, so only values need to go through esc or title.
+type MessageTemplate struct {
+	name     string
+	source   string
+	byFormat map[Format]*template.Template
+}
+
+// templateFuncs are available to every template, on top of the format and language specific ones.
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"toCircle":  toCircle,
+		"pluralize": pluralize,
+		"truncate":  truncate,
+	}
+}
+
+// langFuncs are the template functions bound to the message language. t and tn return
+// translated messages escaped for the format.
+func langFuncs(lang Lang, f Format) template.FuncMap {
+	return template.FuncMap{
+		"t": func(key string, args ...any) string {
+			return f.Escape(lang.T(key, args...))
+		},
+		"tn": func(key string, n int, args ...any) string {
+			return f.Escape(lang.N(key, n, args...))
+		},
+		"formatTime":     lang.FormatTime,
+		"formatDate":     lang.FormatDate,
+		"formatDuration": lang.FormatDuration,
+	}
+}
+
+func ParseMessageTemplate(name, text string) (*MessageTemplate, error) {
+	res := &MessageTemplate{name: name, source: text, byFormat: make(map[Format]*template.Template, len(formats))}
+	for _, f := range formats {
+		t, err := template.New(name).Funcs(templateFuncs()).Funcs(formatFuncs(f)).Funcs(langFuncs(LangEnglish, f)).Parse(text)
+		if err != nil {
+			return nil, res.describe(err)
+		}
+		for _, tt := range t.Templates() {
+			if tt.Tree != nil {
+				escapeTextNodes(tt.Root, f)
+			}
+		}
+		res.byFormat[f] = t
+	}
+	return res, nil
+}
+
+func mustMessageTemplate(name, text string) *MessageTemplate {
+	res, err := ParseMessageTemplate(name, text)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// LoadMessageTemplate reads a task message template from a file and validates it by
+// rendering sample data in every format, so mistakes are reported at startup.
+func LoadMessageTemplate(path string) (*MessageTemplate, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+
+	res, err := ParseMessageTemplate(filepath.Base(path), string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	sample := sampleMessageData()
+	for _, f := range formats {
+		for _, l := range langs {
+			if _, err := res.execute(f, l, sample); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return res, nil
+}
+
+func (t *MessageTemplate) execute(f Format, lang Lang, data any) (string, error) {
+	tmpl := t.byFormat[f]
+	if lang != LangEnglish {
+		var err error
+		if tmpl, err = tmpl.Clone(); err != nil {
+			return "", fmt.Errorf("clone template: %w", err)
+		}
+		tmpl.Funcs(langFuncs(lang, f))
+	}
+
+	buff := &bytes.Buffer{}
+	if err := tmpl.Execute(buff, data); err != nil {
+		return "", fmt.Errorf("execute template: %w", t.describe(err))
+	}
+	return buff.String(), nil
+}
+
+var templateLineRe = regexp.MustCompile(`template: [^:]+:(\d+)`) //nolint:gochecknoglobals // compiled once
+
+// describe adds the offending template line to a parse or execution error.
+func (t *MessageTemplate) describe(err error) error {
+	m := templateLineRe.FindStringSubmatch(err.Error())
+	if m == nil {
+		return err
+	}
+	n, _ := strconv.Atoi(m[1])
+	lines := strings.Split(t.source, "\n")
+	if n < 1 || n > len(lines) {
+		return err
+	}
+	return fmt.Errorf("%w\n%4d | %s", err, n, lines[n-1])
+}
+
+// sampleMessageData exercises every field of MessageData for template validation.
+func sampleMessageData() MessageData {
+	now := time.Date(2026, 1, 11, 15, 0, 0, 0, time.UTC)
+	tasks := []todoist.Task{
+		{ID: "1", ProjectID: "p1", SectionID: "s1", Content: "Sample task", Description: "Details", Priority: int(P1), Labels: []string{"work"},
+			Due: &todoist.TaskDue{Date: "2026-01-11T16:00:00"}, Deadline: &todoist.TaskDeadline{Date: "2026-01-12"},
+			Duration: &todoist.TaskDuration{Amount: 30, Unit: "minute"}},
+		{ID: "2", ProjectID: "p1", Content: "Ignored task", Priority: int(P2), Due: &todoist.TaskDue{Date: "2026-01-10"}},
+		{ID: "3", ProjectID: "p2", Content: "Habit", Priority: int(P4), Due: &todoist.TaskDue{Date: "2026-01-11", IsRecurring: true}},
+	}
+	catalog := NewCatalog(
+		[]todoist.Project{{ID: "p1", Name: "Work"}, {ID: "p2", Name: "Home"}},
+		[]todoist.Section{{ID: "s1", ProjectID: "p1", Name: "Meetings"}},
+	)
+	workload := EstimateWorkload(tasks, now, 22*time.Hour) //nolint:mnd // sample end of day
+	return NewMessageData(tasks, now, RenderOptions{
+		Nagged:   map[string]int{"2": 7}, //nolint:mnd // sample count
+		Workload: &workload,
+		Habits:   true,
+		GroupBy:  GroupBySection,
+		Catalog:  catalog,
+		Links:    LinksWeb,
+		Fields:   TaskFields{Time: true, Labels: true, Description: true, Project: true},
+		Changes: &TaskChanges{
+			Since:     now.Add(-time.Hour),
+			Previous:  map[string]bool{"2": true},
+			Completed: []todoist.Task{{ID: "4", Content: "Done task", Priority: int(P3)}},
+		},
+	})
+}
+
+func escapeTextNodes(node parse.Node, f Format) {
+	switch n := node.(type) {
+	case *parse.TextNode:
+		n.Text = []byte(f.Escape(string(n.Text)))
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			escapeTextNodes(child, f)
+		}
+	case *parse.IfNode:
+		escapeTextNodes(n.List, f)
+		escapeTextNodes(n.ElseList, f)
+	case *parse.RangeNode:
+		escapeTextNodes(n.List, f)
+		escapeTextNodes(n.ElseList, f)
+	case *parse.WithNode:
+		escapeTextNodes(n.List, f)
+		escapeTextNodes(n.ElseList, f)
+	}
+}
+
+// pluralize returns the count with the singular or plural word, e.g. {{ pluralize .Counts.Total "task" "tasks" }}.
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return "1 " + singular
+	}
+	return strconv.Itoa(n) + " " + plural
+}
+
+// truncate shortens s to at most n characters, ending with "…" when cut, e.g. {{ truncate 40 .Content }}.
+func truncate(n int, s string) (string, error) {
+	if n < 1 {
+		return "", errors.New("truncate: length must be positive")
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s, nil
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:n-1]), " ") + "…", nil
+}
@@ -0,0 +1,93 @@
+package internal_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestLoadMessageTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "custom.tmpl")
+	err := os.WriteFile(path, []byte(`{{ formatTime .Now "15:04" }} - {{ pluralize .Counts.Total "task" "tasks" }} ({{ .Counts.P1 }} urgent)!
+{{- range .Tasks }}
+* {{ title . }} [{{ esc .Project }}]{{ if .Overdue }} (overdue){{ end }}
+{{- end }}
+{{- with .Overdue }}
+{{ len . }} overdue: {{ range . }}{{ truncate 6 .Content | esc }} {{ end }}
+{{- end }}
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := internal.LoadMessageTemplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 11, 15, 4, 0, 0, time.UTC)
+	catalog := internal.NewCatalog([]todoist.Project{{ID: "p", Name: "Home"}}, nil)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "Pay bills", Priority: 4, ProjectID: "p", Due: &todoist.TaskDue{Date: "2026-01-11"}},
+		{ID: "2", Content: "Call plumber", Priority: 2, ProjectID: "p", Due: &todoist.TaskDue{Date: "2026-01-09"}},
+	}
+
+	msg, err := singleMessage(internal.RenderTasksMessage(tasks, now, internal.RenderOptions{
+		Template: tmpl,
+		Catalog:  catalog,
+		Format:   internal.FormatMarkdown,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "15:04 \\- 2 tasks \\(1 urgent\\)\\!\n" +
+		"\\* *Pay bills* \\[Home\\]\n" +
+		"\\* Call plumber \\[Home\\] \\(overdue\\)\n" +
+		"1 overdue: Call… \n"
+	if msg != expected {
+		t.Errorf("expected message %q, got %q", expected, msg)
+	}
+}
+
+func TestLoadMessageTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		expected string
+	}{
+		{
+			name:     "parse error",
+			template: "Tasks:\n{{ range .Tasks }}\n- {{ .Content }\n{{ end }}",
+			expected: "   3 | - {{ .Content }",
+		},
+		{
+			name:     "unknown field",
+			template: "Tasks:\n{{ range .Tasks }}\n- {{ .Title }}\n{{ end }}",
+			expected: "   3 | - {{ .Title }}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "broken.tmpl")
+			if err := os.WriteFile(path, []byte(tt.template), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := internal.LoadMessageTemplate(path)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error to show %q, got %q", tt.expected, err)
+			}
+		})
+	}
+}
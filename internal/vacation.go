@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tele "gopkg.in/telebot.v3"
+)
+
+// isDayOff reports whether t falls on a configured holiday or the ad-hoc vacation.
+func (b *Bot) isDayOff(t time.Time) bool {
+	for _, r := range b.conf.DaysOff {
+		if r.Contains(t) {
+			return true
+		}
+	}
+
+	var res bool
+	b.store.View(func(state *State) {
+		res = state.Vacation != nil && state.Vacation.Contains(t)
+	})
+	return res
+}
+
+func (b *Bot) handleVacation(c tele.Context) error {
+	args := c.Args()
+	lang := b.lang(c.Chat().ID)
+
+	switch {
+	case len(args) == 0:
+		var vacation *DateRange
+		b.store.View(func(state *State) {
+			vacation = state.Vacation
+		})
+		today := b.clock.Now().Format(time.DateOnly)
+		if vacation == nil || vacation.To < today {
+			return c.Send(lang.T("vacation.none") + "\n" + lang.T("vacation.usage"))
+		}
+		return c.Send(lang.T("vacation.current", vacation))
+	case len(args) == 1 && strings.EqualFold(args[0], "off"):
+		if err := b.store.Update(func(state *State) error {
+			state.Vacation = nil
+			return nil
+		}); err != nil {
+			return fmt.Errorf("clear vacation: %w", err)
+		}
+		return c.Send(lang.T("vacation.cancelled"))
+	case len(args) == 1 || len(args) == 2:
+		from, to := args[0], args[0]
+		if len(args) == 2 { //nolint:mnd // from and to
+			to = args[1]
+		}
+		vacation, err := ParseDateRange(from, to)
+		if err != nil {
+			return c.Send(lang.T("vacation.invalid", err) + "\n" + lang.T("vacation.usage"))
+		}
+		if err := b.store.Update(func(state *State) error {
+			state.Vacation = &vacation
+			return nil
+		}); err != nil {
+			return fmt.Errorf("save vacation: %w", err)
+		}
+		return c.Send(lang.T("vacation.set", vacation))
+	default:
+		return c.Send(lang.T("vacation.usage"))
+	}
+}
@@ -0,0 +1,148 @@
+package internal_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+	tele "gopkg.in/telebot.v3"
+)
+
+// fakeContext is a command in the test chat. Only the methods used by the handlers are implemented.
+type fakeContext struct {
+	tele.Context
+	args    []string
+	replies []string
+}
+
+func (c *fakeContext) Args() []string {
+	return c.args
+}
+
+func (c *fakeContext) Chat() *tele.Chat {
+	return &tele.Chat{ID: testChatID}
+}
+
+func (c *fakeContext) Send(what any, _ ...any) error {
+	c.replies = append(c.replies, fmt.Sprint(what))
+	return nil
+}
+
+func (f *botFixture) command(t *testing.T, args ...string) string {
+	t.Helper()
+	c := &fakeContext{args: args}
+	if err := f.bot.HandleVacation(c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.replies) != 1 {
+		t.Fatalf("expected a single reply, got %q", c.replies)
+	}
+	return c.replies[0]
+}
+
+func TestBot_HandleVacation(t *testing.T) {
+	f := newBotFixture(t, nil)
+	f.todoist.open = []todoist.Task{today("1", "report", 4)}
+
+	if reply := f.command(t); !strings.HasPrefix(reply, "No vacation planned.") {
+		t.Errorf("expected no vacation, got %q", reply)
+	}
+	if reply := f.command(t, "2026-01-14", "2026-01-10"); !strings.HasPrefix(reply, "Invalid dates") {
+		t.Errorf("expected invalid dates, got %q", reply)
+	}
+
+	if reply := f.command(t, "2026-01-11", "2026-01-13"); reply != "Vacation set: 2026-01-11 – 2026-01-13. Enjoy! 🌴" {
+		t.Errorf("unexpected reply %q", reply)
+	}
+	if reply := f.command(t); reply != "Vacation: 2026-01-11 – 2026-01-13" {
+		t.Errorf("expected the current vacation, got %q", reply)
+	}
+	if msgs := f.send(t); len(msgs) != 0 {
+		t.Fatalf("expected no notification on vacation, got %+v", msgs)
+	}
+	if err := f.bot.SendTasks(testChatID, true); err != nil {
+		t.Fatal(err)
+	}
+	if msgs := f.messenger.Messages(testChatID); len(msgs) != 1 {
+		t.Fatalf("expected /tasks to answer on vacation, got %+v", msgs)
+	}
+
+	if reply := f.command(t, "off"); !strings.HasPrefix(reply, "Vacation cancelled") {
+		t.Errorf("unexpected reply %q", reply)
+	}
+	if msgs := f.send(t); len(msgs) != 2 {
+		t.Errorf("expected notifications after the vacation is cancelled, got %+v", msgs)
+	}
+}
+
+func TestBot_SendTasks_DayOff(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      internal.DaysOffMode
+		daysOff   []internal.DateRange
+		vacation  []string
+		wantText  string
+		wantQuiet bool
+	}{
+		{
+			name:     "vacation skip",
+			mode:     internal.DaysOffSkip,
+			vacation: []string{"2026-01-12"},
+		},
+		{
+			name:    "holiday skip",
+			mode:    internal.DaysOffSkip,
+			daysOff: []internal.DateRange{{From: "2026-01-11", To: "2026-01-12"}},
+		},
+		{
+			name:      "vacation downgrade",
+			mode:      internal.DaysOffDowngrade,
+			vacation:  []string{"2026-01-12", "2026-01-13"},
+			wantText:  "Uncompleted tasks for today:\n- 🔴 report\n",
+			wantQuiet: true,
+		},
+		{
+			name:      "holiday downgrade",
+			mode:      internal.DaysOffDowngrade,
+			daysOff:   []internal.DateRange{{From: "2026-01-12", To: "2026-01-12"}},
+			wantText:  "Uncompleted tasks for today:\n- 🔴 report\n",
+			wantQuiet: true,
+		},
+		{
+			name:     "not a day off",
+			mode:     internal.DaysOffSkip,
+			daysOff:  []internal.DateRange{{From: "2026-01-13", To: "2026-01-13"}},
+			wantText: "Uncompleted tasks for today:\n- 🔴 report\n- 🟠 review\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBotFixture(t, func(conf *internal.Config) {
+				conf.DaysOffMode = tt.mode
+				conf.DaysOff = tt.daysOff
+				conf.NagThreshold = 0
+			})
+			f.todoist.open = []todoist.Task{today("1", "report", 4), today("2", "review", 3)}
+			// P2 tasks are revealed at 3pm
+			f.clock.now = f.clock.now.Add(6 * time.Hour)
+			if tt.vacation != nil {
+				f.command(t, tt.vacation...)
+			}
+
+			msgs := f.send(t)
+			if tt.wantText == "" {
+				if len(msgs) != 0 {
+					t.Errorf("expected no notification, got %+v", msgs)
+				}
+				return
+			}
+			if len(msgs) != 1 || msgs[0].Text != tt.wantText || msgs[0].Silent != tt.wantQuiet {
+				t.Errorf("expected message %q (silent: %t), got %+v", tt.wantText, tt.wantQuiet, msgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// WebhookVersion is the version of the webhook payload. It changes on incompatible changes only.
+	WebhookVersion = 1
+
+	// webhookRetries is how many times a failed delivery is retried, with exponential backoff.
+	webhookRetries = 3
+	// webhookRetryDelay is the backoff before the first retry, it doubles after every retry.
+	webhookRetryDelay = time.Second
+
+	webhookTimestampHeader = "X-Todoist-Notifier-Timestamp"
+	webhookSignatureHeader = "X-Todoist-Notifier-Signature"
+	webhookRunIDHeader     = "X-Todoist-Notifier-Run-Id"
+)
+
+// WebhookChannel POSTs digests as signed JSON documents to an arbitrary URL.
+//
+// The X-Todoist-Notifier-Signature header is "sha256=" followed by the hex HMAC-SHA256 of
+// the X-Todoist-Notifier-Timestamp header (unix seconds), a dot and the request body.
+type WebhookChannel struct {
+	url        string
+	secret     []byte
+	client     HTTPClient
+	retries    int
+	retryDelay time.Duration
+}
+
+type webhookPayload struct {
+	Version   int              `json:"version"`
+	RunID     string           `json:"run_id"`
+	Timestamp time.Time        `json:"timestamp"`
+	Language  Lang             `json:"language"`
+	Tasks     []webhookTask    `json:"tasks"`
+	Filter    []FilterDecision `json:"filter"`
+}
+
+type webhookTask struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	// Kind is "task", "pending" (long-ignored, see NAG_THRESHOLD) or "habit".
+	Kind string `json:"kind"`
+	// Priority is 1 for P1 (highest) to 4 for P4, unlike the inverted Todoist API value.
+	Priority    int      `json:"priority"`
+	Description string   `json:"description,omitempty"`
+	Project     string   `json:"project,omitempty"`
+	Section     string   `json:"section,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	Due         string   `json:"due,omitempty"`
+	Deadline    string   `json:"deadline,omitempty"`
+	Overdue     bool     `json:"overdue"`
+	Reminders   int      `json:"reminders,omitempty"`
+	URL         string   `json:"url"`
+}
+
+// NewWebhookChannel returns a webhook channel that signs payloads with secret and retries failed
+// deliveries up to retries times, waiting retryDelay before the first retry and doubling it after.
+func NewWebhookChannel(url, secret string, client HTTPClient, retries int, retryDelay time.Duration) *WebhookChannel {
+	return &WebhookChannel{url: url, secret: []byte(secret), client: client, retries: retries, retryDelay: retryDelay}
+}
+
+func (c *WebhookChannel) Name() string {
+	if u, err := url.Parse(c.url); err == nil && u.Host != "" {
+		return "webhook " + u.Host
+	}
+	return "webhook"
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, digest Digest) error {
+	body, err := json.Marshal(newWebhookPayload(digest))
+	if err != nil {
+		return fmt.Errorf("encode webhook payload: %w", err)
+	}
+	timestamp := strconv.FormatInt(digest.Now.Unix(), 10)
+	header := http.Header{
+		webhookTimestampHeader: {timestamp},
+		webhookSignatureHeader: {"sha256=" + c.sign(timestamp, body)},
+		webhookRunIDHeader:     {digest.RunID},
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := post(ctx, c.client, c.url, "application/json", body, header)
+		if err == nil {
+			if err = resp.checkStatus(); err == nil {
+				return nil
+			}
+			if !retryableStatus(resp.StatusCode) {
+				return fmt.Errorf("post webhook: %w", err)
+			}
+		}
+		if attempt == c.retries {
+			return fmt.Errorf("post webhook after %d attempts: %w", attempt+1, err)
+		}
+		if err := sleep(ctx, c.retryDelay<<attempt); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *WebhookChannel) sign(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryableStatus reports whether a request may succeed when repeated later.
+func retryableStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests || code == http.StatusRequestTimeout
+}
+
+func newWebhookPayload(d Digest) webhookPayload {
+	res := webhookPayload{
+		Version:   WebhookVersion,
+		RunID:     d.RunID,
+		Timestamp: d.Now,
+		Language:  d.Lang,
+		Tasks:     make([]webhookTask, 0, len(d.Pending)+len(d.Tasks)+len(d.Habits)),
+		Filter:    d.Decisions,
+	}
+	for _, kind := range []struct {
+		name  string
+		tasks []TaskData
+	}{{"pending", d.Pending}, {"task", d.Tasks}, {"habit", d.Habits}} {
+		for _, t := range kind.tasks {
+			res.Tasks = append(res.Tasks, newWebhookTask(t, kind.name, d.Now.Location()))
+		}
+	}
+	if res.Filter == nil {
+		res.Filter = []FilterDecision{}
+	}
+	return res
+}
+
+func newWebhookTask(t TaskData, kind string, loc *time.Location) webhookTask {
+	res := webhookTask{
+		ID:          t.ID,
+		Content:     t.Content,
+		Kind:        kind,
+		Priority:    int(P1) + 1 - t.Priority,
+		Description: t.Task.Description,
+		Project:     t.Project,
+		Section:     t.Section,
+		Labels:      t.Labels,
+		Due:         dueDate(t.Task, loc),
+		Overdue:     t.Overdue,
+		Reminders:   t.Reminders,
+		URL:         t.URL,
+	}
+	if t.Task.Deadline != nil {
+		res.Deadline = t.Task.Deadline.Date
+	}
+	return res
+}
@@ -0,0 +1,148 @@
+package internal_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestWebhookChannel(t *testing.T) {
+	srv, requests, bodies := recordingServer(t, http.StatusOK)
+	digest := testDigest()
+	digest.Decisions = []internal.FilterDecision{
+		{TaskID: "1", Reason: internal.FilterIncluded},
+		{TaskID: "9", Reason: internal.FilterNotDue},
+	}
+
+	if err := internal.NewWebhookChannel(srv.URL, "s3cret", srv.Client(), 0, 0).Send(context.Background(), digest); err != nil {
+		t.Fatal(err)
+	}
+
+	req, body := (*requests)[0], (*bodies)[0]
+	timestamp := req.Header.Get("X-Todoist-Notifier-Timestamp")
+	if timestamp != "1768125600" {
+		t.Errorf("expected the digest time as timestamp, got %q", timestamp)
+	}
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); req.Header.Get("X-Todoist-Notifier-Signature") != want {
+		t.Errorf("expected signature %q, got %q", want, req.Header.Get("X-Todoist-Notifier-Signature"))
+	}
+	if req.Header.Get("X-Todoist-Notifier-Run-Id") != "run-1" {
+		t.Errorf("unexpected run ID header %q", req.Header.Get("X-Todoist-Notifier-Run-Id"))
+	}
+
+	var payload struct {
+		Version   int       `json:"version"`
+		RunID     string    `json:"run_id"`
+		Timestamp time.Time `json:"timestamp"`
+		Tasks     []struct {
+			ID       string `json:"id"`
+			Kind     string `json:"kind"`
+			Priority int    `json:"priority"`
+			Project  string `json:"project"`
+			URL      string `json:"url"`
+		} `json:"tasks"`
+		Filter []struct {
+			TaskID string `json:"task_id"`
+			Reason string `json:"reason"`
+		} `json:"filter"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Version != internal.WebhookVersion || payload.RunID != "run-1" || !payload.Timestamp.Equal(digest.Now) {
+		t.Errorf("unexpected envelope %+v", payload)
+	}
+	if len(payload.Tasks) != 4 {
+		t.Fatalf("expected 4 tasks, got %+v", payload.Tasks)
+	}
+	first, habit := payload.Tasks[0], payload.Tasks[3]
+	if first.ID != "1" || first.Kind != "task" || first.Priority != 1 || first.Project != "Work" || first.URL == "" {
+		t.Errorf("unexpected first task %+v", first)
+	}
+	if habit.ID != "4" || habit.Kind != "habit" || habit.Priority != 4 {
+		t.Errorf("unexpected habit %+v", habit)
+	}
+	if len(payload.Filter) != 2 || payload.Filter[1].Reason != "not_due" {
+		t.Errorf("unexpected filter decisions %+v", payload.Filter)
+	}
+}
+
+func TestWebhookChannel_Retries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "recovers from server errors", statuses: []int{503, 500, 200}, wantCalls: 3},
+		{name: "gives up after retries", statuses: []int{503, 503, 503, 503, 200}, wantCalls: 4, wantErr: true},
+		{name: "client errors are final", statuses: []int{400, 200}, wantCalls: 1, wantErr: true},
+		{name: "rate limits are retried", statuses: []int{429, 200}, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			t.Cleanup(srv.Close)
+
+			err := internal.NewWebhookChannel(srv.URL, "s3cret", srv.Client(), 3, time.Millisecond).Send(context.Background(), testDigest())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestWebhookChannel_EscalatedPriority(t *testing.T) {
+	srv, _, bodies := recordingServer(t, http.StatusOK)
+	webhook := internal.NewWebhookChannel(srv.URL, "s3cret", srv.Client(), 0, 0)
+	f := newBotFixture(t, func(conf *internal.Config) {
+		conf.DeadlineWindow = 48 * time.Hour
+	}, webhook)
+	// a P4 task due in two days, escalated by its deadline tomorrow
+	escalated := todoist.Task{ID: "1", Content: "report", Priority: 1,
+		Due: &todoist.TaskDue{Date: "2026-01-14"}, Deadline: &todoist.TaskDeadline{Date: "2026-01-13"}}
+	f.todoist.open = []todoist.Task{escalated}
+
+	msgs := f.send(t)
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Text, "🔴 report") {
+		t.Fatalf("expected the Telegram message to show the task as P1, got %+v", msgs)
+	}
+
+	var payload struct {
+		Tasks []struct {
+			Priority int `json:"priority"`
+		} `json:"tasks"`
+		Filter []struct {
+			Reason string `json:"reason"`
+		} `json:"filter"`
+	}
+	if err := json.Unmarshal((*bodies)[0], &payload); err != nil {
+		t.Fatal(err)
+	}
+	if len(payload.Tasks) != 1 || payload.Tasks[0].Priority != 4 {
+		t.Errorf("expected the original P4 priority, got %+v", payload.Tasks)
+	}
+	if len(payload.Filter) != 1 || payload.Filter[0].Reason != "deadline_escalated" {
+		t.Errorf("expected the escalation in the filter decisions, got %+v", payload.Filter)
+	}
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+// Workload compares the estimated work left for today with the time left until the end of the day.
+type Workload struct {
+	Planned   time.Duration
+	Available time.Duration
+	EndOfDay  time.Time
+}
+
+func (w *Workload) Overbooked() bool {
+	return w != nil && w.Planned > w.Available
+}
+
+// EstimateWorkload sums the durations of tasks and compares them with the time left until endOfDay
+// (time since midnight). Only durations in minutes are counted; "day" durations describe
+// multi-day spans rather than effort.
+func EstimateWorkload(tasks []todoist.Task, now time.Time, endOfDay time.Duration) Workload {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	res := Workload{EndOfDay: midnight.Add(endOfDay)}
+	res.Available = max(res.EndOfDay.Sub(now), 0)
+
+	for _, t := range tasks {
+		if t.Duration != nil && t.Duration.Unit == "minute" {
+			res.Planned += time.Duration(t.Duration.Amount) * time.Minute
+		}
+	}
+
+	return res
+}
+
+// dueToday returns the tasks due on the day of now. Tasks escalated by a close deadline are shown
+// ahead of their due day, their work is not planned for today.
+func dueToday(tasks []todoist.Task, now time.Time) []todoist.Task {
+	today := now.Format(time.DateOnly)
+	res := make([]todoist.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if dueDate(t, now.Location()) == today {
+			res = append(res, t)
+		}
+	}
+	return res
+}
@@ -0,0 +1,49 @@
+package internal_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Roma7-7-7/todoist-notifier/internal"
+	"github.com/Roma7-7-7/todoist-notifier/pkg/todoist"
+)
+
+func TestEstimateWorkload(t *testing.T) {
+	now := time.Date(2026, 1, 11, 18, 30, 0, 0, time.UTC)
+	tasks := []todoist.Task{
+		{ID: "1", Content: "write report", Priority: 4, Duration: &todoist.TaskDuration{Amount: 180, Unit: "minute"}},
+		{ID: "2", Content: "review", Priority: 3, Duration: &todoist.TaskDuration{Amount: 90, Unit: "minute"}},
+		{ID: "3", Content: "trip", Priority: 2, Duration: &todoist.TaskDuration{Amount: 2, Unit: "day"}},
+		{ID: "4", Content: "call", Priority: 1},
+	}
+
+	workload := internal.EstimateWorkload(tasks, now, 22*time.Hour)
+	if workload.Planned != 270*time.Minute {
+		t.Errorf("expected 4h30m planned, got %s", workload.Planned)
+	}
+	if workload.Available != 210*time.Minute {
+		t.Errorf("expected 3h30m available, got %s", workload.Available)
+	}
+	if !workload.Overbooked() {
+		t.Error("expected workload to be overbooked")
+	}
+
+	msg, err := singleMessage(internal.RenderTasksMessage(tasks[:1], now, internal.RenderOptions{Workload: &workload}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(msg, "⚠️ Overbooked: 4h30m of estimated work, 3h30m left until 22:00\n") {
+		t.Errorf("expected overbooking warning, got %q", msg)
+	}
+
+	midnight := internal.EstimateWorkload(tasks, now, 24*time.Hour)
+	if midnight.Available != 330*time.Minute || midnight.Overbooked() {
+		t.Errorf("expected 5h30m left until midnight and no overbooking, got %+v", midnight)
+	}
+
+	late := internal.EstimateWorkload(tasks[3:], now.Add(5*time.Hour), 22*time.Hour)
+	if late.Available != 0 || late.Overbooked() {
+		t.Errorf("expected no time left and no overbooking without durations, got %+v", late)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -22,18 +23,47 @@ type (
 	}
 
 	Task struct {
-		ID        string   `json:"id"`
-		ProjectID string   `json:"project_id"`
-		Content   string   `json:"content"`
-		Priority  int      `json:"priority"`
-		Due       *TaskDue `json:"due"`
-		Labels    []string `json:"labels"`
+		ID          string        `json:"id"`
+		ProjectID   string        `json:"project_id"`
+		SectionID   string        `json:"section_id"`
+		Content     string        `json:"content"`
+		Description string        `json:"description"`
+		Priority    int           `json:"priority"`
+		Due         *TaskDue      `json:"due"`
+		Deadline    *TaskDeadline `json:"deadline"`
+		Duration    *TaskDuration `json:"duration"`
+		Labels      []string      `json:"labels"`
+		ChildOrder  int           `json:"child_order"`
 	}
 
 	TaskDue struct {
+		Date        string `json:"date"`
+		IsRecurring bool   `json:"is_recurring"`
+	}
+
+	TaskDeadline struct {
 		Date string `json:"date"`
 	}
 
+	TaskDuration struct {
+		Amount int    `json:"amount"`
+		Unit   string `json:"unit"` // "minute" or "day"
+	}
+
+	Project struct {
+		ID         string `json:"id"`
+		ParentID   string `json:"parent_id"`
+		Name       string `json:"name"`
+		ChildOrder int    `json:"child_order"`
+	}
+
+	Section struct {
+		ID           string `json:"id"`
+		ProjectID    string `json:"project_id"`
+		Name         string `json:"name"`
+		SectionOrder int    `json:"section_order"`
+	}
+
 	UpdateTaskRequest struct {
 		Priority int      `json:"priority,omitempty,omitzero"`
 		Labels   []string `json:"labels,omitempty,omitzero"`
@@ -58,31 +88,89 @@ func NewClient(token string, httpClient HTTPClient, retriesCount int, retriesDel
 	}
 }
 
-type getTasksResponseBody struct {
-	Results []Task `json:"results"`
+type resultsResponseBody[T any] struct {
+	Results []T `json:"results"`
 }
 
 func (c *Client) GetTasksLimit200(ctx context.Context, isCompleted bool) ([]Task, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/tasks?limit=200", nil)
+	q := url.Values{}
+	q.Add("is_completed", fmt.Sprintf("%t", isCompleted))
+
+	var res resultsResponseBody[Task]
+	if err := c.get(ctx, "/tasks", q, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Results, nil
+}
+
+// GetCompletedTasks returns all tasks completed between since and until, 200 per request.
+func (c *Client) GetCompletedTasks(ctx context.Context, since, until time.Time) ([]Task, error) {
+	var (
+		items  []Task
+		cursor string
+	)
+	for {
+		q := url.Values{}
+		q.Add("since", since.UTC().Format(time.RFC3339))
+		q.Add("until", until.UTC().Format(time.RFC3339))
+		if cursor != "" {
+			q.Add("cursor", cursor)
+		}
+
+		var res struct {
+			Items      []Task `json:"items"`
+			NextCursor string `json:"next_cursor"`
+		}
+		if err := c.get(ctx, "/tasks/completed/by_completion_date", q, &res); err != nil {
+			return nil, err
+		}
+
+		items = append(items, res.Items...)
+		if res.NextCursor == "" {
+			return items, nil
+		}
+		cursor = res.NextCursor
+	}
+}
+
+func (c *Client) GetProjects(ctx context.Context) ([]Project, error) {
+	var res resultsResponseBody[Project]
+	if err := c.get(ctx, "/projects", url.Values{}, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Results, nil
+}
+
+func (c *Client) GetSections(ctx context.Context) ([]Section, error) {
+	var res resultsResponseBody[Section]
+	if err := c.get(ctx, "/sections", url.Values{}, &res); err != nil {
+		return nil, err
+	}
+
+	return res.Results, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, q url.Values, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return fmt.Errorf("create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.token)
 
-	q := req.URL.Query()
-	q.Add("is_completed", fmt.Sprintf("%t", isCompleted))
+	q.Set("limit", "200")
 	req.URL.RawQuery = q.Encode()
 
 	c.log.DebugContext(ctx, "sending request",
 		"url", req.URL.String(),
 		"method", req.Method,
-		"token", c.token,
-		"is_completed", isCompleted)
+		"token", c.token)
 
 	resp, err := c.doWithRetry(ctx, req)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close() //nolint:errcheck // ignore
 
@@ -93,15 +181,14 @@ func (c *Client) GetTasksLimit200(ctx context.Context, isCompleted bool) ([]Task
 		n, _ := resp.Body.Read(body)
 		c.log.DebugContext(ctx, "response payload", "payload", string(body[:n]))
 
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	var res getTasksResponseBody
-	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if err = json.NewDecoder(resp.Body).Decode(target); err != nil {
+		return fmt.Errorf("decode response: %w", err)
 	}
 
-	return res.Results, nil
+	return nil
 }
 
 func (c *Client) doWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {